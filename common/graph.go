@@ -0,0 +1,183 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package common
+
+import (
+	"os"
+	"sort"
+
+	"qpm.io/qpm/core"
+)
+
+// Edge is a dependency declared by one package on another.
+type Edge struct {
+	Name       string
+	Constraint string
+	// Package is the installed package, or nil if it is missing.
+	Package *PackageWrapper
+}
+
+// Missing reports whether the package is not installed.
+func (e Edge) Missing() bool {
+	return e.Package == nil
+}
+
+// Satisfied reports whether the package is installed with a version allowed by the range.
+func (e Edge) Satisfied() bool {
+	return e.Package != nil && e.Package.Version != nil && Satisfies(e.Package.Version.Label, e.Constraint)
+}
+
+// DependencyGraph links a root package file with the packages installed in the vendor
+// directory through the dependencies each of them declares.
+type DependencyGraph struct {
+	Root     *PackageWrapper
+	Packages map[string]*PackageWrapper
+}
+
+func NewDependencyGraph(root *PackageWrapper, packages map[string]*PackageWrapper) *DependencyGraph {
+	return &DependencyGraph{
+		Root:     root,
+		Packages: packages,
+	}
+}
+
+// LoadDependencyGraph builds the graph for the root package from the packages installed
+// in vendorDir. A missing vendor directory results in every dependency being missing.
+func LoadDependencyGraph(root *PackageWrapper, vendorDir string) (*DependencyGraph, error) {
+	packages, err := LoadPackages(vendorDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return NewDependencyGraph(root, packages), nil
+}
+
+// RootName returns a name for the root package, which may not have one if it is an app.
+func (g DependencyGraph) RootName() string {
+	if g.Root.Name != "" {
+		return g.Root.Name
+	}
+	return core.PackageFile
+}
+
+// Edges returns the dependencies declared by the package, sorted by name.
+func (g DependencyGraph) Edges(pkg *PackageWrapper) []Edge {
+	edges := []Edge{}
+	if pkg == nil {
+		return edges
+	}
+	for _, d := range pkg.Dependencies {
+		name, constraint := ParseDependency(d)
+		edges = append(edges, Edge{
+			Name:       name,
+			Constraint: constraint,
+			Package:    g.Packages[name],
+		})
+	}
+	sort.Sort(edgesByName(edges))
+	return edges
+}
+
+type edgesByName []Edge
+
+func (e edgesByName) Len() int           { return len(e) }
+func (e edgesByName) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e edgesByName) Less(i, j int) bool { return e[i].Name < e[j].Name }
+
+// Paths returns every path through the graph from the root to the named package. Each
+// path is the list of edges followed, so the last edge always leads to the target.
+func (g DependencyGraph) Paths(target string) [][]Edge {
+	paths := [][]Edge{}
+	onPath := make(map[string]bool)
+
+	var walk func(pkg *PackageWrapper, path []Edge)
+	walk = func(pkg *PackageWrapper, path []Edge) {
+		for _, e := range g.Edges(pkg) {
+			if onPath[e.Name] {
+				continue
+			}
+			current := append(path[:len(path):len(path)], e)
+			if e.Name == target {
+				paths = append(paths, current)
+				continue
+			}
+			if e.Package != nil {
+				onPath[e.Name] = true
+				walk(e.Package, current)
+				onPath[e.Name] = false
+			}
+		}
+	}
+	walk(g.Root, []Edge{})
+
+	return paths
+}
+
+// Constraints returns the version ranges placed on the named package by the root and by
+// every installed package that depends on it.
+func (g DependencyGraph) Constraints(name string) []string {
+	constraints := []string{}
+
+	collect := func(pkg *PackageWrapper) {
+		for _, e := range g.Edges(pkg) {
+			if e.Name == name {
+				constraints = append(constraints, e.Constraint)
+			}
+		}
+	}
+
+	collect(g.Root)
+	for _, pkg := range g.Packages {
+		collect(pkg)
+	}
+
+	return constraints
+}
+
+// Sorted returns the installed packages in an order where every package comes before the
+// packages it depends on. Packages that could come in either order are sorted by name, so
+// the order only changes when the packages do. Packages in a dependency cycle are also
+// ordered by name.
+func (g DependencyGraph) Sorted() []*PackageWrapper {
+	// The number of installed packages that depend on each package and have not been
+	// added to the result yet
+	dependents := make(map[string]int)
+	for _, pkg := range g.Packages {
+		for _, e := range g.Edges(pkg) {
+			if e.Package != nil && e.Name != pkg.Name {
+				dependents[e.Name]++
+			}
+		}
+	}
+
+	var remaining []string
+	for name := range g.Packages {
+		remaining = append(remaining, name)
+	}
+	sort.Strings(remaining)
+
+	sorted := []*PackageWrapper{}
+	for len(remaining) > 0 {
+		// Take the first package by name that nothing else still needs, or the first one
+		// left if the rest form a cycle
+		next := 0
+		for i, name := range remaining {
+			if dependents[name] == 0 {
+				next = i
+				break
+			}
+		}
+
+		pkg := g.Packages[remaining[next]]
+		remaining = append(remaining[:next], remaining[next+1:]...)
+		sorted = append(sorted, pkg)
+
+		for _, e := range g.Edges(pkg) {
+			if e.Package != nil && e.Name != pkg.Name {
+				dependents[e.Name]--
+			}
+		}
+	}
+
+	return sorted
+}
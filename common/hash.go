@@ -0,0 +1,103 @@
+// Copyright 2015 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"qpm.io/qpm/core"
+)
+
+// SHA-256 hashing
+
+func hash(path string) ([]byte, error) {
+
+	var result []byte
+	file, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return result, err
+	}
+
+	return hash.Sum(result), nil
+}
+
+// HashPaths produces a single SHA-256 of the contents of the given files. Directories and
+// the signature file are skipped.
+func HashPaths(paths []string) (string, error) {
+
+	var result []byte
+
+	// we need to sort to get consistent results
+	sort.Strings(paths)
+
+	master := sha256.New()
+
+	for _, p := range paths {
+		f, err := os.Stat(p)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasSuffix(p, core.SignatureFile) {
+			continue
+		}
+		if !f.IsDir() {
+			sha, err := hash(p)
+			if err != nil {
+				fmt.Println(err)
+				return "", err
+			}
+			master.Write(sha)
+		}
+	}
+
+	result = master.Sum(nil)
+
+	return hex.EncodeToString(result), nil
+}
+
+// HashTree walks the given directory, skipping any VCS metadata, and hashes the files it
+// contains using HashPaths.
+func HashTree(directory string) (string, error) {
+
+	paths := []string{}
+
+	err := filepath.Walk(directory, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() {
+			if skipDir(f.Name()) {
+				return filepath.SkipDir
+			}
+		} else if f.Name() != ".git" {
+			// A submodule has a .git file that points at its repository instead of a
+			// .git directory
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return HashPaths(paths)
+}
+
+// skipDir reports whether HashTree leaves out the directory with the given name.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".git") || name == ".hg"
+}
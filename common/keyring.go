@@ -0,0 +1,302 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"qpm.io/qpm/core"
+)
+
+// KeyringFile is the name of the file in core.ConfigDir that stores the public keys of package
+// publishers.
+const KeyringFile = "keyring.json"
+
+// AnyPackage is the prefix that trusts a key for every package.
+const AnyPackage = "*"
+
+// TrustedKey is the public key of a publisher and the packages it is trusted to sign.
+type TrustedKey struct {
+	Fingerprint string `json:"fingerprint"`
+	Identity    string `json:"identity"`
+	// Prefixes select the packages the key is trusted for. The prefix "com.example"
+	// matches com.example and every package whose name starts with "com.example.".
+	Prefixes  []string `json:"prefixes"`
+	PublicKey string   `json:"publicKey"`
+}
+
+// Keyring holds the keys used to verify package signatures.
+type Keyring struct {
+	path string
+	Keys []*TrustedKey `json:"keys"`
+	// Pins maps package names to the fingerprint of the key that was trusted the first time
+	// the package was installed.
+	Pins map[string]string `json:"pins"`
+	// Revoked holds the fingerprints of the keys that were removed, which are not fetched
+	// again until they are imported explicitly.
+	Revoked []string `json:"revoked"`
+}
+
+// KeyringPath returns the path of the keyring file.
+func KeyringPath() string {
+	return filepath.Join(core.ConfigDir(), KeyringFile)
+}
+
+// LoadKeyring reads the keyring file. A missing file has no keys.
+func LoadKeyring() (*Keyring, error) {
+	keyring := &Keyring{
+		path:    KeyringPath(),
+		Keys:    []*TrustedKey{},
+		Pins:    make(map[string]string),
+		Revoked: []string{},
+	}
+
+	data, err := ioutil.ReadFile(keyring.path)
+	if os.IsNotExist(err) {
+		return keyring, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, keyring); err != nil {
+		return nil, fmt.Errorf("%s: %v", keyring.path, err)
+	}
+	if keyring.Keys == nil {
+		keyring.Keys = []*TrustedKey{}
+	}
+	if keyring.Pins == nil {
+		keyring.Pins = make(map[string]string)
+	}
+	if keyring.Revoked == nil {
+		keyring.Revoked = []string{}
+	}
+	return keyring, nil
+}
+
+// Save writes the keyring file.
+func (k *Keyring) Save() error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(k.path, append(data, '\n'), 0644)
+}
+
+// Find returns the key with the given fingerprint, or nil if it is not in the keyring.
+func (k *Keyring) Find(fingerprint string) *TrustedKey {
+	fingerprint = NormalizeFingerprint(fingerprint)
+	for _, key := range k.Keys {
+		if key.Fingerprint == fingerprint {
+			return key
+		}
+	}
+	return nil
+}
+
+// Import adds the armored public keys read from r and returns them. Keys that are already
+// in the keyring are updated and keep the packages they are trusted for, and keys that were
+// revoked are no longer. Only the public part of a private key is stored.
+func (k *Keyring) Import(r io.Reader) ([]*TrustedKey, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var imported []*TrustedKey
+	for _, entity := range entities {
+		if len(entity.Revocations) > 0 {
+			return nil, fmt.Errorf("the key %s has been revoked", KeyFingerprint(entity))
+		}
+
+		publicKey, err := ArmoredPublicKey(entity)
+		if err != nil {
+			return nil, err
+		}
+
+		fingerprint := KeyFingerprint(entity)
+		k.unrevoke(fingerprint)
+		key := k.Find(fingerprint)
+		if key == nil {
+			key = &TrustedKey{
+				Fingerprint: fingerprint,
+				Prefixes:    []string{},
+			}
+			k.Keys = append(k.Keys, key)
+		}
+		key.Identity = identity(entity)
+		key.PublicKey = publicKey
+		imported = append(imported, key)
+	}
+
+	return imported, nil
+}
+
+// ImportKey adds the armored public key, which must be the only key in publicKey and have
+// the given fingerprint. This is used for keys from untrusted sources such as a registry, so
+// revoked keys are refused.
+func (k *Keyring) ImportKey(publicKey string, fingerprint string) (*TrustedKey, error) {
+	fingerprint = NormalizeFingerprint(fingerprint)
+	if k.IsRevoked(fingerprint) {
+		return nil, fmt.Errorf("the key %s has been revoked", fingerprint)
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) != 1 || KeyFingerprint(entities[0]) != fingerprint {
+		return nil, fmt.Errorf("the key is not %s", fingerprint)
+	}
+
+	keys, err := k.Import(strings.NewReader(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+// Pin records that the package with the given name is signed with the key.
+func (k *Keyring) Pin(name string, fingerprint string) {
+	k.Pins[name] = NormalizeFingerprint(fingerprint)
+}
+
+// Pinned returns the fingerprint of the key that the package is pinned to, or an empty
+// string if it is not pinned.
+func (k *Keyring) Pinned(name string) string {
+	return k.Pins[name]
+}
+
+// Trusts reports whether the key is trusted for the package with the given name, either
+// because it was trusted for a prefix of the name or because the package is pinned to it.
+func (k *Keyring) Trusts(key *TrustedKey, name string) bool {
+	return key.Trusts(name) || k.Pins[name] == key.Fingerprint
+}
+
+// Revoke stops trusting the key for the given prefix or pinned package.
+func (k *Keyring) Revoke(key *TrustedKey, prefix string) error {
+	if k.Pins[prefix] == key.Fingerprint {
+		delete(k.Pins, prefix)
+		return nil
+	}
+	return key.Revoke(prefix)
+}
+
+// PinnedPackages returns the names of the packages pinned to the key in sorted order.
+func (k *Keyring) PinnedPackages(key *TrustedKey) []string {
+	var names []string
+	for name, fingerprint := range k.Pins {
+		if fingerprint == key.Fingerprint {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Remove deletes the key with the given fingerprint from the keyring and marks it as
+// revoked.
+func (k *Keyring) Remove(fingerprint string) error {
+	fingerprint = NormalizeFingerprint(fingerprint)
+	for n, key := range k.Keys {
+		if key.Fingerprint == fingerprint {
+			k.Keys = append(k.Keys[:n], k.Keys[n+1:]...)
+			for _, name := range k.PinnedPackages(key) {
+				delete(k.Pins, name)
+			}
+			k.Revoked = append(k.Revoked, fingerprint)
+			sort.Strings(k.Revoked)
+			return nil
+		}
+	}
+	return fmt.Errorf("The key %s is not in the keyring", fingerprint)
+}
+
+// IsRevoked reports whether the key with the given fingerprint was removed from the keyring.
+func (k *Keyring) IsRevoked(fingerprint string) bool {
+	fingerprint = NormalizeFingerprint(fingerprint)
+	for _, revoked := range k.Revoked {
+		if revoked == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// unrevoke forgets that the key with the given fingerprint was revoked.
+func (k *Keyring) unrevoke(fingerprint string) {
+	for n, revoked := range k.Revoked {
+		if revoked == fingerprint {
+			k.Revoked = append(k.Revoked[:n], k.Revoked[n+1:]...)
+			return
+		}
+	}
+}
+
+// identity returns the primary user id of the entity.
+func identity(entity *openpgp.Entity) string {
+	var names []string
+	for name, id := range entity.Identities {
+		if id.SelfSignature != nil && id.SelfSignature.IsPrimaryId != nil && *id.SelfSignature.IsPrimaryId {
+			return name
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// Entity parses the public key.
+func (tk *TrustedKey) Entity() (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(tk.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("the key %s is corrupt: %v", tk.Fingerprint, err)
+	}
+	return entities[0], nil
+}
+
+// Trust allows the key to sign the packages with the given prefix.
+func (tk *TrustedKey) Trust(prefix string) {
+	for _, p := range tk.Prefixes {
+		if p == prefix {
+			return
+		}
+	}
+	tk.Prefixes = append(tk.Prefixes, prefix)
+	sort.Strings(tk.Prefixes)
+}
+
+// Revoke stops trusting the key for the packages with the given prefix.
+func (tk *TrustedKey) Revoke(prefix string) error {
+	for n, p := range tk.Prefixes {
+		if p == prefix {
+			tk.Prefixes = append(tk.Prefixes[:n], tk.Prefixes[n+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("The key %s is not trusted for %s", tk.Fingerprint, prefix)
+}
+
+// Trusts reports whether the key is trusted to sign the package with the given name.
+func (tk *TrustedKey) Trusts(name string) bool {
+	for _, p := range tk.Prefixes {
+		if p == AnyPackage || name == p || strings.HasPrefix(name, p+".") {
+			return true
+		}
+	}
+	return false
+}
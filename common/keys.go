@@ -0,0 +1,43 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+// The helpers in this file are shared by the keyring and the registry, so that both
+// identify and store keys the same way.
+
+// NormalizeFingerprint returns the fingerprint in lower case without spaces, which is how
+// fingerprints are stored in the keyring.
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.Replace(fingerprint, " ", "", -1))
+}
+
+// KeyFingerprint returns the fingerprint of the entity in the form used by the keyring.
+func KeyFingerprint(entity *openpgp.Entity) string {
+	return hex.EncodeToString(entity.PrimaryKey.Fingerprint[:])
+}
+
+// ArmoredPublicKey returns the public part of the entity in armored form.
+func ArmoredPublicKey(entity *openpgp.Entity) (string, error) {
+	var buffer bytes.Buffer
+	writer, err := armor.Encode(&buffer, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", err
+	}
+	if err = entity.Serialize(writer); err != nil {
+		return "", err
+	}
+	if err = writer.Close(); err != nil {
+		return "", err
+	}
+	return buffer.String() + "\n", nil
+}
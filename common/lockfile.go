@@ -0,0 +1,165 @@
+// Copyright 2015 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+// LockedRepository is the repository a locked dependency was installed from.
+type LockedRepository struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+}
+
+// LockedDependency records exactly which release of a package was installed.
+type LockedDependency struct {
+	Name       string           `json:"name"`
+	Repository LockedRepository `json:"repository"`
+	Version    string           `json:"version"`
+	Revision   string           `json:"revision"`
+	Hash       string           `json:"hash,omitempty"`
+}
+
+// NewLockedDependency creates a lock entry for the given dependency whose installed
+// content hashes to hash.
+func NewLockedDependency(d *msg.Dependency, hash string) *LockedDependency {
+	return &LockedDependency{
+		Name: d.Name,
+		Repository: LockedRepository{
+			Type: d.Repository.Type.String(),
+			Url:  d.Repository.Url,
+		},
+		Version:  d.Version.Label,
+		Revision: d.Version.Revision,
+		Hash:     hash,
+	}
+}
+
+// Dependency converts the lock entry back into the message used by the installers.
+func (ld LockedDependency) Dependency() *msg.Dependency {
+	return &msg.Dependency{
+		Name: ld.Name,
+		Repository: &msg.Package_Repository{
+			Type: msg.RepoType(msg.RepoType_value[ld.Repository.Type]),
+			Url:  ld.Repository.Url,
+		},
+		Version: &msg.Package_Version{
+			Label:    ld.Version,
+			Revision: ld.Revision,
+		},
+	}
+}
+
+func (ld LockedDependency) Signature() string {
+	return strings.Join([]string{ld.Name, ld.Version}, "@")
+}
+
+// LockFile contains every transitive dependency that was resolved for a package file, so
+// that later installs can reproduce the same tree without asking the server again.
+type LockFile struct {
+	// Checksum of the dependency list in the package file at the time of locking.
+	PackageHash  string              `json:"packageHash"`
+	Dependencies []*LockedDependency `json:"dependencies"`
+	FilePath     string              `json:"-"`
+}
+
+func NewLockFile(file string) *LockFile {
+	return &LockFile{
+		Dependencies: []*LockedDependency{},
+		FilePath:     file,
+	}
+}
+
+// LoadLockFile reads the core.LockFile contained in path. As with LoadPackage, the
+// returned error satisfies os.IsNotExist if there is no such file.
+func LoadLockFile(path string) (*LockFile, error) {
+	lock := NewLockFile(filepath.Join(path, core.LockFile))
+
+	file, err := os.Open(lock.FilePath)
+	if err != nil {
+		return lock, err
+	}
+	defer file.Close()
+
+	if err = json.NewDecoder(file).Decode(lock); err != nil {
+		return lock, err
+	}
+
+	lock.FilePath, err = filepath.Abs(file.Name())
+	return lock, err
+}
+
+func (lf LockFile) Save() error {
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(lf.FilePath, append(data, '\n'), 0644)
+}
+
+// Find returns the locked entry for the named package, or nil if it is not locked.
+func (lf LockFile) Find(name string) *LockedDependency {
+	for _, d := range lf.Dependencies {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// Put adds the given entry to the lock, replacing any existing entry for the same package.
+func (lf *LockFile) Put(dep *LockedDependency) {
+	for i, d := range lf.Dependencies {
+		if d.Name == dep.Name {
+			lf.Dependencies[i] = dep
+			return
+		}
+	}
+	lf.Dependencies = append(lf.Dependencies, dep)
+	sort.Sort(byName(lf.Dependencies))
+}
+
+// Remove drops the named package from the lock.
+func (lf *LockFile) Remove(name string) {
+	for i, d := range lf.Dependencies {
+		if d.Name == name {
+			lf.Dependencies = append(lf.Dependencies[:i], lf.Dependencies[i+1:]...)
+			return
+		}
+	}
+}
+
+// Matches reports whether the lock was created from the current dependencies of pkg.
+func (lf LockFile) Matches(pkg *PackageWrapper) bool {
+	return lf.PackageHash == DependencyHash(pkg.Dependencies)
+}
+
+// DependencyHash returns an order independent checksum of a list of dependency signatures.
+func DependencyHash(dependencies []string) string {
+	sorted := make([]string, len(dependencies))
+	for i, d := range dependencies {
+		sorted[i] = strings.ToLower(d)
+	}
+	sort.Strings(sorted)
+
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+type byName []*LockedDependency
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
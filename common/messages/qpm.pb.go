@@ -31,6 +31,10 @@ It has these top-level messages:
 	InfoResponse
 	LicenseRequest
 	LicenseResponse
+	PublishKeyRequest
+	PublishKeyResponse
+	KeyRequest
+	KeyResponse
 */
 package messages
 
@@ -181,16 +185,21 @@ func (m *DependencyMessage) String() string            { return proto.CompactTex
 func (*DependencyMessage) ProtoMessage()               {}
 func (*DependencyMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+
+
 type Package struct {
-	Name         string              `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Description  string              `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
-	Author       *Package_Author     `protobuf:"bytes,3,opt,name=author" json:"author,omitempty"`
-	Repository   *Package_Repository `protobuf:"bytes,4,opt,name=repository" json:"repository,omitempty"`
-	Version      *Package_Version    `protobuf:"bytes,5,opt,name=version" json:"version,omitempty"`
-	Dependencies []string            `protobuf:"bytes,6,rep,name=dependencies" json:"dependencies,omitempty"`
-	License      LicenseType         `protobuf:"varint,7,opt,name=license,enum=messages.LicenseType" json:"license,omitempty"`
-	PriFilename  string              `protobuf:"bytes,8,opt,name=pri_filename,json=priFilename" json:"pri_filename,omitempty"`
-	Webpage      string              `protobuf:"bytes,10,opt,name=webpage" json:"webpage,omitempty"`
+	Name          string              `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Description   string              `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
+	Author        *Package_Author     `protobuf:"bytes,3,opt,name=author" json:"author,omitempty"`
+	Repository    *Package_Repository `protobuf:"bytes,4,opt,name=repository" json:"repository,omitempty"`
+	Version       *Package_Version    `protobuf:"bytes,5,opt,name=version" json:"version,omitempty"`
+	Dependencies  []string            `protobuf:"bytes,6,rep,name=dependencies" json:"dependencies,omitempty"`
+	License       LicenseType         `protobuf:"varint,7,opt,name=license,enum=messages.LicenseType" json:"license,omitempty"`
+	PriFilename   string              `protobuf:"bytes,8,opt,name=pri_filename,json=priFilename" json:"pri_filename,omitempty"`
+	Webpage       string              `protobuf:"bytes,10,opt,name=webpage" json:"webpage,omitempty"`
+	VendorDir     string              `protobuf:"bytes,11,opt,name=vendor_dir,json=vendorDir" json:"vendor_dir,omitempty"`
+	CmakeFilename string              `protobuf:"bytes,12,opt,name=cmake_filename,json=cmakeFilename" json:"cmake_filename,omitempty"`
+	BuildSystems  []string            `protobuf:"bytes,13,rep,name=build_systems,json=buildSystems" json:"build_systems,omitempty"`
 }
 
 func (m *Package) Reset()                    { *m = Package{} }
@@ -233,6 +242,7 @@ type Package_Version struct {
 	Label       string `protobuf:"bytes,1,opt,name=label" json:"label,omitempty"`
 	Revision    string `protobuf:"bytes,2,opt,name=revision" json:"revision,omitempty"`
 	Fingerprint string `protobuf:"bytes,3,opt,name=fingerprint" json:"fingerprint,omitempty"`
+	Hash        string `protobuf:"bytes,4,opt,name=hash" json:"hash,omitempty"`
 }
 
 func (m *Package_Version) Reset()                    { *m = Package_Version{} }
@@ -544,6 +554,45 @@ func (m *LicenseResponse) String() string            { return proto.CompactTextS
 func (*LicenseResponse) ProtoMessage()               {}
 func (*LicenseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
+type PublishKeyRequest struct {
+	Token     string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	PublicKey string `protobuf:"bytes,2,opt,name=public_key,json=publicKey" json:"public_key,omitempty"`
+}
+
+func (m *PublishKeyRequest) Reset()                    { *m = PublishKeyRequest{} }
+func (m *PublishKeyRequest) String() string            { return proto.CompactTextString(m) }
+func (*PublishKeyRequest) ProtoMessage()               {}
+func (*PublishKeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+type PublishKeyResponse struct {
+	Fingerprint string `protobuf:"bytes,1,opt,name=fingerprint" json:"fingerprint,omitempty"`
+}
+
+func (m *PublishKeyResponse) Reset()                    { *m = PublishKeyResponse{} }
+func (m *PublishKeyResponse) String() string            { return proto.CompactTextString(m) }
+func (*PublishKeyResponse) ProtoMessage()               {}
+func (*PublishKeyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+type KeyRequest struct {
+	Fingerprint string `protobuf:"bytes,1,opt,name=fingerprint" json:"fingerprint,omitempty"`
+	PackageName string `protobuf:"bytes,2,opt,name=package_name,json=packageName" json:"package_name,omitempty"`
+}
+
+func (m *KeyRequest) Reset()                    { *m = KeyRequest{} }
+func (m *KeyRequest) String() string            { return proto.CompactTextString(m) }
+func (*KeyRequest) ProtoMessage()               {}
+func (*KeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+type KeyResponse struct {
+	Fingerprint string `protobuf:"bytes,1,opt,name=fingerprint" json:"fingerprint,omitempty"`
+	PublicKey   string `protobuf:"bytes,2,opt,name=public_key,json=publicKey" json:"public_key,omitempty"`
+}
+
+func (m *KeyResponse) Reset()                    { *m = KeyResponse{} }
+func (m *KeyResponse) String() string            { return proto.CompactTextString(m) }
+func (*KeyResponse) ProtoMessage()               {}
+func (*KeyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
 func init() {
 	proto.RegisterType((*DependencyMessage)(nil), "messages.DependencyMessage")
 	proto.RegisterType((*Package)(nil), "messages.Package")
@@ -570,6 +619,10 @@ func init() {
 	proto.RegisterType((*InfoResponse)(nil), "messages.InfoResponse")
 	proto.RegisterType((*LicenseRequest)(nil), "messages.LicenseRequest")
 	proto.RegisterType((*LicenseResponse)(nil), "messages.LicenseResponse")
+	proto.RegisterType((*PublishKeyRequest)(nil), "messages.PublishKeyRequest")
+	proto.RegisterType((*PublishKeyResponse)(nil), "messages.PublishKeyResponse")
+	proto.RegisterType((*KeyRequest)(nil), "messages.KeyRequest")
+	proto.RegisterType((*KeyResponse)(nil), "messages.KeyResponse")
 	proto.RegisterEnum("messages.RepoType", RepoType_name, RepoType_value)
 	proto.RegisterEnum("messages.LicenseType", LicenseType_name, LicenseType_value)
 	proto.RegisterEnum("messages.MessageType", MessageType_name, MessageType_value)
@@ -594,6 +647,8 @@ type QpmClient interface {
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 	Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
 	GetLicense(ctx context.Context, in *LicenseRequest, opts ...grpc.CallOption) (*LicenseResponse, error)
+	PublishKey(ctx context.Context, in *PublishKeyRequest, opts ...grpc.CallOption) (*PublishKeyResponse, error)
+	GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*KeyResponse, error)
 }
 
 type qpmClient struct {
@@ -676,6 +731,24 @@ func (c *qpmClient) GetLicense(ctx context.Context, in *LicenseRequest, opts ...
 	return out, nil
 }
 
+func (c *qpmClient) PublishKey(ctx context.Context, in *PublishKeyRequest, opts ...grpc.CallOption) (*PublishKeyResponse, error) {
+	out := new(PublishKeyResponse)
+	err := grpc.Invoke(ctx, "/messages.Qpm/PublishKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *qpmClient) GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*KeyResponse, error) {
+	out := new(KeyResponse)
+	err := grpc.Invoke(ctx, "/messages.Qpm/GetKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Qpm service
 
 type QpmServer interface {
@@ -687,6 +760,8 @@ type QpmServer interface {
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 	Info(context.Context, *InfoRequest) (*InfoResponse, error)
 	GetLicense(context.Context, *LicenseRequest) (*LicenseResponse, error)
+	PublishKey(context.Context, *PublishKeyRequest) (*PublishKeyResponse, error)
+	GetKey(context.Context, *KeyRequest) (*KeyResponse, error)
 }
 
 func RegisterQpmServer(s *grpc.Server, srv QpmServer) {
@@ -837,6 +912,42 @@ func _Qpm_GetLicense_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Qpm_PublishKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QpmServer).PublishKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/messages.Qpm/PublishKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QpmServer).PublishKey(ctx, req.(*PublishKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Qpm_GetKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QpmServer).GetKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/messages.Qpm/GetKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QpmServer).GetKey(ctx, req.(*KeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Qpm_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "messages.Qpm",
 	HandlerType: (*QpmServer)(nil),
@@ -873,6 +984,14 @@ var _Qpm_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetLicense",
 			Handler:    _Qpm_GetLicense_Handler,
 		},
+		{
+			MethodName: "PublishKey",
+			Handler:    _Qpm_PublishKey_Handler,
+		},
+		{
+			MethodName: "GetKey",
+			Handler:    _Qpm_GetKey_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -881,86 +1000,95 @@ var _Qpm_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("qpm.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1281 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x57, 0x4b, 0x6f, 0xdb, 0xc6,
-	0x13, 0x37, 0xad, 0x07, 0xa5, 0xa1, 0x28, 0x33, 0xfb, 0x4f, 0x1c, 0x46, 0xff, 0x1c, 0x5c, 0x16,
-	0x29, 0xdc, 0x14, 0x70, 0x14, 0xf9, 0x90, 0xa0, 0x4d, 0x80, 0xc8, 0xb2, 0xe2, 0x10, 0x90, 0x15,
-	0x77, 0x25, 0xb7, 0xbd, 0x14, 0x04, 0x2d, 0x6d, 0x6c, 0x36, 0x14, 0xc9, 0x90, 0x74, 0x02, 0xdd,
-	0x8a, 0xa2, 0x40, 0x3f, 0x40, 0x3f, 0x59, 0xcf, 0x3d, 0xf7, 0xd0, 0x6f, 0x51, 0xec, 0x8b, 0x5a,
-	0x3d, 0x5a, 0x38, 0xb9, 0x69, 0x66, 0xe7, 0xb5, 0x33, 0xbf, 0xf9, 0x2d, 0x05, 0xf5, 0x77, 0xc9,
-	0xec, 0x20, 0x49, 0xe3, 0x3c, 0x46, 0xb5, 0x19, 0xc9, 0x32, 0xff, 0x92, 0x64, 0xce, 0xcf, 0x1a,
-	0xdc, 0x3a, 0x26, 0x09, 0x89, 0xa6, 0x24, 0x9a, 0xcc, 0x4f, 0xb9, 0x1a, 0x7d, 0x09, 0xe5, 0x7c,
-	0x9e, 0x10, 0x5b, 0xdb, 0xd3, 0xf6, 0x9b, 0x9d, 0x3b, 0x07, 0xd2, 0xfc, 0x40, 0x18, 0x8c, 0xe7,
-	0x09, 0xc1, 0xcc, 0x04, 0xdd, 0x86, 0x4a, 0x1e, 0xe4, 0x21, 0xb1, 0xb7, 0xf7, 0xb4, 0xfd, 0x3a,
-	0xe6, 0x02, 0x42, 0x50, 0xbe, 0x88, 0xa7, 0x73, 0xbb, 0xc4, 0x94, 0xec, 0x37, 0xda, 0x85, 0x6a,
-	0x92, 0xc6, 0xb3, 0x24, 0xb7, 0xcb, 0x7b, 0xda, 0x7e, 0x0d, 0x0b, 0xc9, 0xf9, 0xa3, 0x0c, 0xfa,
-	0x99, 0x3f, 0x79, 0x4b, 0x13, 0x23, 0x28, 0x47, 0xfe, 0x8c, 0x27, 0xae, 0x63, 0xf6, 0x1b, 0xed,
-	0x81, 0x31, 0x25, 0xd9, 0x24, 0x0d, 0x92, 0x3c, 0x88, 0x23, 0x91, 0x47, 0x55, 0xa1, 0x36, 0x54,
-	0xfd, 0xeb, 0xfc, 0x2a, 0x4e, 0x59, 0x3e, 0xa3, 0x63, 0x2f, 0x0a, 0x16, 0x81, 0x0f, 0xba, 0xec,
-	0x1c, 0x0b, 0x3b, 0xf4, 0x0c, 0x20, 0x25, 0x49, 0x9c, 0x05, 0x79, 0x9c, 0xce, 0x59, 0x3d, 0x46,
-	0xe7, 0xfe, 0xba, 0x17, 0x2e, 0x6c, 0xb0, 0x62, 0x8f, 0x0e, 0x41, 0x7f, 0x4f, 0xd2, 0x8c, 0x56,
-	0x53, 0x61, 0xae, 0xf7, 0xd6, 0x5d, 0xbf, 0xe3, 0x06, 0x58, 0x5a, 0x22, 0x07, 0x1a, 0x53, 0xd9,
-	0xe8, 0x80, 0x64, 0x76, 0x75, 0xaf, 0xb4, 0x5f, 0xc7, 0x4b, 0x3a, 0xf4, 0x08, 0xf4, 0x30, 0x98,
-	0x90, 0x28, 0x23, 0xb6, 0xbe, 0xda, 0xfa, 0x01, 0x3f, 0x60, 0xad, 0x97, 0x56, 0xe8, 0x33, 0x68,
-	0x24, 0x69, 0xe0, 0xbd, 0x09, 0x42, 0xc2, 0xfa, 0x56, 0xe3, 0xcd, 0x49, 0xd2, 0xe0, 0xa5, 0x50,
-	0x21, 0x1b, 0xf4, 0x0f, 0xe4, 0x22, 0xf1, 0x2f, 0x89, 0x0d, 0xec, 0x54, 0x8a, 0xad, 0x97, 0x00,
-	0x8b, 0x0b, 0xa2, 0x2f, 0x96, 0x66, 0x8e, 0x16, 0x89, 0xa9, 0x8d, 0x32, 0x70, 0x0b, 0x4a, 0xd7,
-	0x69, 0x28, 0xc6, 0x40, 0x7f, 0xb6, 0x7e, 0x04, 0x5d, 0xdc, 0x96, 0xa2, 0x21, 0xf4, 0x2f, 0x48,
-	0x28, 0x06, 0xc8, 0x05, 0xd4, 0x82, 0x5a, 0x4a, 0xde, 0x07, 0xd9, 0x62, 0x7c, 0x85, 0x4c, 0xa7,
-	0xfb, 0x26, 0x88, 0x2e, 0x49, 0x9a, 0xa4, 0x41, 0x94, 0x0b, 0xc0, 0xa8, 0xaa, 0x56, 0x07, 0xaa,
-	0x7c, 0x7a, 0x1b, 0xd1, 0x71, 0x1b, 0x2a, 0x64, 0xe6, 0x07, 0xb2, 0x20, 0x2e, 0x38, 0xbf, 0x6b,
-	0x00, 0x0b, 0x58, 0x6f, 0x74, 0x5c, 0x86, 0xc0, 0xf6, 0xa7, 0x43, 0xa0, 0x74, 0x53, 0x08, 0x38,
-	0x01, 0x18, 0x42, 0xe7, 0x46, 0x6f, 0x62, 0x35, 0x86, 0x76, 0x63, 0x18, 0x3d, 0x80, 0xe6, 0xd4,
-	0xcf, 0x89, 0x97, 0x5c, 0x5f, 0x84, 0x41, 0x76, 0x45, 0xa6, 0xe2, 0xe2, 0x26, 0xd5, 0x9e, 0x49,
-	0xa5, 0xf3, 0xa7, 0x06, 0x8d, 0x11, 0xf1, 0xd3, 0xc9, 0x15, 0x26, 0xd9, 0x75, 0x98, 0x6f, 0x6c,
-	0x81, 0xbd, 0x28, 0x80, 0x07, 0x29, 0xb2, 0x7c, 0xfc, 0x46, 0xad, 0x6c, 0x69, 0x79, 0x7d, 0x4b,
-	0x15, 0x70, 0x57, 0x6e, 0x04, 0x6e, 0x05, 0xb9, 0xd5, 0x25, 0xe4, 0x3a, 0xbf, 0x6a, 0xd0, 0x70,
-	0xa3, 0x2c, 0xf7, 0xc3, 0x70, 0x94, 0xfb, 0x79, 0x46, 0x51, 0x30, 0xf5, 0x83, 0x70, 0xce, 0xae,
-	0x67, 0x62, 0x2e, 0x50, 0xc6, 0xf9, 0x40, 0xc8, 0xdb, 0x90, 0x8f, 0xd7, 0xc4, 0x42, 0xa2, 0x81,
-	0x67, 0x71, 0x94, 0x5f, 0x85, 0x9c, 0xa0, 0x4c, 0x2c, 0x45, 0xea, 0x31, 0x27, 0x7e, 0x1a, 0x72,
-	0x4e, 0x30, 0xb1, 0x90, 0x18, 0xcb, 0xc5, 0xb9, 0x1f, 0xb2, 0xca, 0x4d, 0xcc, 0x05, 0xc7, 0x04,
-	0xe3, 0x2c, 0x88, 0x2e, 0x31, 0x79, 0x77, 0x4d, 0xb2, 0xdc, 0x69, 0x42, 0x83, 0x8b, 0x59, 0x12,
-	0x47, 0x19, 0x71, 0x7e, 0x82, 0xa6, 0x18, 0x88, 0xb0, 0x40, 0x47, 0xf0, 0xbf, 0x84, 0xb7, 0xcf,
-	0x53, 0x9b, 0xc5, 0xa7, 0x7f, 0x6b, 0xad, 0xc7, 0x18, 0x09, 0xeb, 0x63, 0xa5, 0x8d, 0xac, 0x94,
-	0xb7, 0x24, 0x2a, 0x08, 0x97, 0x0a, 0xce, 0x2d, 0xd8, 0x29, 0x72, 0x89, 0xf4, 0xef, 0x55, 0x66,
-	0x97, 0x15, 0x7c, 0x0e, 0xa6, 0xac, 0x80, 0x42, 0x20, 0xb3, 0x35, 0x4e, 0x43, 0x42, 0x39, 0xa4,
-	0x3a, 0xf4, 0x0c, 0x9a, 0x93, 0x78, 0x96, 0xf8, 0xb9, 0x27, 0x07, 0x56, 0xfe, 0xaf, 0x81, 0x99,
-	0xdc, 0x58, 0xa8, 0x9c, 0xdf, 0x34, 0x40, 0x6a, 0x62, 0x5e, 0x0e, 0x7a, 0xba, 0xc2, 0x7f, 0x34,
-	0xb1, 0xd1, 0xb9, 0xbd, 0x08, 0xa9, 0xf8, 0x2c, 0xb3, 0xe2, 0x13, 0x28, 0xde, 0x2b, 0x7b, 0x9b,
-	0x79, 0xfd, 0x7f, 0x93, 0x97, 0x78, 0x9b, 0xf0, 0xe2, 0x71, 0xeb, 0x80, 0x29, 0x77, 0x80, 0xdf,
-	0x9e, 0xd2, 0xa5, 0x72, 0x7b, 0xb1, 0x0c, 0x86, 0x72, 0x79, 0xe7, 0x08, 0x9a, 0xc5, 0xde, 0xf0,
-	0xc2, 0xdb, 0xa0, 0xa7, 0x6c, 0x87, 0x64, 0xcd, 0xbb, 0x8b, 0xec, 0xea, 0x8a, 0x61, 0x69, 0x46,
-	0x71, 0x31, 0x08, 0xb2, 0x5c, 0xe2, 0xe2, 0x05, 0x34, 0xb8, 0xf8, 0xc9, 0x01, 0x7f, 0x80, 0xc6,
-	0x20, 0xbe, 0x0c, 0x22, 0x79, 0x8f, 0x82, 0xf4, 0x34, 0x85, 0xf4, 0x28, 0xcd, 0x26, 0x7e, 0x96,
-	0x7d, 0x88, 0x53, 0x49, 0x0a, 0x85, 0x4c, 0x81, 0x3d, 0x49, 0x89, 0x9f, 0x13, 0x86, 0xf8, 0x1a,
-	0x16, 0x92, 0xf3, 0x00, 0x4c, 0x11, 0x59, 0x14, 0x57, 0xc0, 0x4b, 0x53, 0xe1, 0xd5, 0x06, 0x83,
-	0x52, 0xd6, 0x47, 0xf4, 0xf1, 0x2f, 0xb6, 0xa2, 0xd4, 0x45, 0x04, 0xfe, 0x0a, 0x74, 0x71, 0xfe,
-	0xef, 0x78, 0x97, 0x16, 0xe8, 0x31, 0xd4, 0x04, 0x15, 0xc9, 0x91, 0x2b, 0xd8, 0x53, 0x38, 0x14,
-	0x17, 0x66, 0x6b, 0xf8, 0x2a, 0xdd, 0x18, 0x5f, 0xdf, 0x80, 0x19, 0x70, 0x32, 0xf1, 0x32, 0xca,
-	0x26, 0xe2, 0x7b, 0x40, 0x99, 0x8a, 0xca, 0x35, 0xb8, 0x11, 0x28, 0x92, 0xf3, 0x1c, 0x9a, 0x02,
-	0xf8, 0xb2, 0x39, 0x1f, 0x73, 0x51, 0xe7, 0x01, 0xec, 0x14, 0xee, 0xa2, 0x51, 0xf2, 0xdb, 0x49,
-	0x5b, 0x7c, 0x3b, 0x3d, 0x7c, 0x0a, 0x35, 0xf9, 0x0c, 0xa3, 0x1a, 0x94, 0xbb, 0xe7, 0xe3, 0xd7,
-	0xd6, 0x16, 0x02, 0xa8, 0x9e, 0xb8, 0xe3, 0x57, 0xe7, 0x47, 0x96, 0x86, 0x74, 0x28, 0x9d, 0xb8,
-	0x63, 0x6b, 0x1b, 0x99, 0x50, 0x3f, 0xed, 0xe3, 0xde, 0x39, 0x76, 0xbb, 0x03, 0xab, 0xf4, 0xf0,
-	0x6f, 0x8d, 0x82, 0xb1, 0x58, 0x56, 0xea, 0x3d, 0x7c, 0x3d, 0xec, 0x5b, 0x5b, 0xd4, 0xe3, 0xd4,
-	0x1d, 0x5b, 0x1a, 0x6a, 0x40, 0xad, 0x7b, 0x72, 0x36, 0xf0, 0x0e, 0xbd, 0xb6, 0xb5, 0x8d, 0x9a,
-	0x00, 0xdd, 0xb3, 0x6e, 0xef, 0x55, 0xdf, 0xeb, 0x78, 0x6d, 0xab, 0x84, 0x2c, 0x68, 0x74, 0xf1,
-	0xd8, 0x1d, 0x8d, 0xdd, 0x1e, 0xd3, 0x94, 0xa9, 0xe6, 0x68, 0x74, 0xec, 0x75, 0xbc, 0xde, 0xa0,
-	0x7b, 0x3e, 0xea, 0x5b, 0x15, 0xa9, 0x39, 0x94, 0x9a, 0x2a, 0x32, 0x40, 0xef, 0xf5, 0xda, 0xde,
-	0x63, 0xaf, 0x6d, 0xe9, 0x54, 0xe8, 0x9f, 0x0d, 0x98, 0x50, 0xa3, 0x02, 0x4d, 0x46, 0x43, 0xd5,
-	0xa5, 0x40, 0x33, 0x03, 0x2d, 0xc8, 0x1d, 0xf5, 0x2c, 0x83, 0x16, 0x34, 0xe0, 0x36, 0x8f, 0xad,
-	0x46, 0x21, 0x51, 0x23, 0x93, 0x5e, 0xef, 0x7c, 0x38, 0x70, 0x7b, 0xfd, 0xe1, 0xa8, 0x6f, 0x35,
-	0x69, 0x80, 0x53, 0x11, 0x6d, 0xe7, 0xe1, 0x23, 0x30, 0x94, 0x0f, 0x54, 0x7a, 0x55, 0x77, 0xf8,
-	0x92, 0x36, 0xca, 0x00, 0xfd, 0xfb, 0x2e, 0x1e, 0xba, 0xc3, 0x13, 0x4b, 0x43, 0x75, 0xa8, 0xf4,
-	0x31, 0x7e, 0x8d, 0xad, 0xed, 0xce, 0x2f, 0x65, 0x28, 0x7d, 0x9b, 0xcc, 0xd0, 0x13, 0x28, 0x53,
-	0xe6, 0x46, 0x0a, 0xc8, 0x14, 0x62, 0x6f, 0xed, 0xae, 0xaa, 0x05, 0xc3, 0x6e, 0xa1, 0x17, 0xa0,
-	0x0b, 0xda, 0x45, 0xea, 0x13, 0xb9, 0xc4, 0xfa, 0xad, 0x7b, 0x1b, 0x4e, 0x8a, 0x08, 0x43, 0xd8,
-	0x39, 0x21, 0xf9, 0xb1, 0x8a, 0xc7, 0x8d, 0xec, 0x26, 0x83, 0xdd, 0xdf, 0x7c, 0x58, 0xc4, 0x7b,
-	0x0e, 0x55, 0xce, 0x21, 0xe8, 0xee, 0x3a, 0xab, 0xf0, 0x10, 0xf6, 0x06, 0xba, 0x91, 0xee, 0x4f,
-	0xa0, 0x4c, 0xb9, 0x0a, 0x2d, 0x51, 0x7d, 0x41, 0x65, 0x6a, 0x27, 0x54, 0x4a, 0x73, 0xb6, 0xd0,
-	0xd7, 0x50, 0x61, 0x44, 0x82, 0x54, 0x13, 0x85, 0xb3, 0x5a, 0x77, 0xd7, 0xf4, 0x6a, 0x52, 0xf6,
-	0x41, 0x74, 0x47, 0xdd, 0xb8, 0x82, 0x6d, 0x5a, 0xbb, 0xab, 0xea, 0xc2, 0xb1, 0x07, 0x70, 0x42,
-	0xe4, 0xc3, 0xa3, 0x4e, 0x60, 0x79, 0x25, 0xd5, 0x09, 0xac, 0x6c, 0x9b, 0xb3, 0x75, 0x51, 0x65,
-	0xff, 0x89, 0x0e, 0xff, 0x09, 0x00, 0x00, 0xff, 0xff, 0xe4, 0xca, 0xea, 0x7c, 0x20, 0x0d, 0x00,
-	0x00,
+	// 1437 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0x4b, 0x6f, 0xdb, 0xc6,
+	0x16, 0x36, 0xad, 0xf7, 0xa1, 0x28, 0xd3, 0x73, 0xf3, 0x60, 0x74, 0x73, 0x01, 0x87, 0x81, 0x2f,
+	0x7c, 0x73, 0x01, 0x47, 0x91, 0x81, 0x26, 0x68, 0x13, 0x20, 0xb2, 0xac, 0x28, 0x42, 0x64, 0xc5,
+	0x19, 0xc9, 0x6d, 0x77, 0x04, 0x2d, 0x8d, 0x6d, 0xd6, 0x14, 0xc9, 0x90, 0xb4, 0x03, 0xed, 0xba,
+	0x28, 0xda, 0x1f, 0xd0, 0x3f, 0xd6, 0x7d, 0xd7, 0x5d, 0xf4, 0x5f, 0x14, 0xf3, 0xa2, 0x46, 0x96,
+	0xda, 0xd8, 0xd9, 0xe9, 0x9c, 0x39, 0xaf, 0x39, 0xf3, 0x9d, 0xef, 0x50, 0x50, 0xf9, 0x18, 0x4d,
+	0x77, 0xa3, 0x38, 0x4c, 0x43, 0x54, 0x9e, 0x92, 0x24, 0x71, 0xcf, 0x48, 0x62, 0xff, 0xa8, 0xc1,
+	0xe6, 0x01, 0x89, 0x48, 0x30, 0x21, 0xc1, 0x78, 0x76, 0xc8, 0xd5, 0xe8, 0x7f, 0x90, 0x4f, 0x67,
+	0x11, 0xb1, 0xb4, 0x2d, 0x6d, 0xa7, 0xd6, 0xbc, 0xbb, 0x2b, 0xcd, 0x77, 0x85, 0xc1, 0x68, 0x16,
+	0x11, 0xcc, 0x4c, 0xd0, 0x1d, 0x28, 0xa4, 0x5e, 0xea, 0x13, 0x6b, 0x7d, 0x4b, 0xdb, 0xa9, 0x60,
+	0x2e, 0x20, 0x04, 0xf9, 0x93, 0x70, 0x32, 0xb3, 0x72, 0x4c, 0xc9, 0x7e, 0xa3, 0x7b, 0x50, 0x8c,
+	0xe2, 0x70, 0x1a, 0xa5, 0x56, 0x7e, 0x4b, 0xdb, 0x29, 0x63, 0x21, 0xd9, 0xbf, 0x15, 0xa0, 0x74,
+	0xe4, 0x8e, 0x2f, 0x68, 0x62, 0x04, 0xf9, 0xc0, 0x9d, 0xf2, 0xc4, 0x15, 0xcc, 0x7e, 0xa3, 0x2d,
+	0xd0, 0x27, 0x24, 0x19, 0xc7, 0x5e, 0x94, 0x7a, 0x61, 0x20, 0xf2, 0xa8, 0x2a, 0xd4, 0x80, 0xa2,
+	0x7b, 0x99, 0x9e, 0x87, 0x31, 0xcb, 0xa7, 0x37, 0xad, 0x79, 0xc1, 0x22, 0xf0, 0x6e, 0x8b, 0x9d,
+	0x63, 0x61, 0x87, 0x5e, 0x02, 0xc4, 0x24, 0x0a, 0x13, 0x2f, 0x0d, 0xe3, 0x19, 0xab, 0x47, 0x6f,
+	0x3e, 0x5c, 0xf6, 0xc2, 0x99, 0x0d, 0x56, 0xec, 0xd1, 0x1e, 0x94, 0xae, 0x48, 0x9c, 0xd0, 0x6a,
+	0x0a, 0xcc, 0xf5, 0xc1, 0xb2, 0xeb, 0xb7, 0xdc, 0x00, 0x4b, 0x4b, 0x64, 0x43, 0x75, 0x22, 0x1b,
+	0xed, 0x91, 0xc4, 0x2a, 0x6e, 0xe5, 0x76, 0x2a, 0x78, 0x41, 0x87, 0x9e, 0x42, 0xc9, 0xf7, 0xc6,
+	0x24, 0x48, 0x88, 0x55, 0xba, 0xde, 0xfa, 0x3e, 0x3f, 0x60, 0xad, 0x97, 0x56, 0xe8, 0x11, 0x54,
+	0xa3, 0xd8, 0x73, 0x4e, 0x3d, 0x9f, 0xb0, 0xbe, 0x95, 0x79, 0x73, 0xa2, 0xd8, 0x7b, 0x23, 0x54,
+	0xc8, 0x82, 0xd2, 0x27, 0x72, 0x12, 0xb9, 0x67, 0xc4, 0x02, 0x76, 0x2a, 0x45, 0xf4, 0x1f, 0x80,
+	0x2b, 0x12, 0x4c, 0xc2, 0xd8, 0x99, 0x78, 0xb1, 0xa5, 0xb3, 0xc3, 0x0a, 0xd7, 0x1c, 0x78, 0x31,
+	0xda, 0x86, 0xda, 0x78, 0xea, 0x5e, 0x90, 0x79, 0xf4, 0x2a, 0x33, 0x31, 0x98, 0x36, 0x8b, 0xff,
+	0x18, 0x8c, 0x93, 0x4b, 0xcf, 0x9f, 0x38, 0xc9, 0x2c, 0x49, 0xc9, 0x34, 0xb1, 0x0c, 0x7e, 0x31,
+	0xa6, 0x1c, 0x72, 0x5d, 0xfd, 0x0d, 0xc0, 0xbc, 0x97, 0xe8, 0xbf, 0x0b, 0xf0, 0x42, 0xf3, 0x3b,
+	0x52, 0x1b, 0x05, 0x5b, 0x26, 0xe4, 0x2e, 0x63, 0x5f, 0xbc, 0x38, 0xfd, 0x59, 0xff, 0x08, 0x25,
+	0xd1, 0x58, 0x0a, 0x3c, 0xdf, 0x3d, 0x21, 0xbe, 0xc0, 0x0a, 0x17, 0x50, 0x1d, 0xca, 0x31, 0xb9,
+	0xf2, 0x92, 0x39, 0x52, 0x32, 0x99, 0x02, 0xe9, 0xd4, 0x0b, 0xce, 0x48, 0x1c, 0xc5, 0x5e, 0x90,
+	0x0a, 0x6c, 0xaa, 0x2a, 0x0a, 0xbf, 0x73, 0x37, 0x39, 0x67, 0x80, 0xa8, 0x60, 0xf6, 0xbb, 0xde,
+	0x84, 0x22, 0x07, 0xcf, 0x4a, 0x70, 0xde, 0x81, 0x02, 0x99, 0xba, 0x9e, 0x2c, 0x92, 0x0b, 0xf6,
+	0xaf, 0x1a, 0xc0, 0x7c, 0xaa, 0x56, 0x3a, 0x2e, 0x22, 0x70, 0xfd, 0xcb, 0x11, 0x98, 0xbb, 0x29,
+	0x02, 0x6d, 0x0f, 0x74, 0xa1, 0xeb, 0x05, 0xa7, 0xa1, 0x1a, 0x43, 0xbb, 0x31, 0x8a, 0xb7, 0xa1,
+	0x36, 0x71, 0x53, 0xe2, 0x44, 0x97, 0x27, 0xbe, 0x97, 0x9c, 0x93, 0x89, 0xb8, 0xb8, 0x41, 0xb5,
+	0x47, 0x52, 0x69, 0xff, 0xae, 0x41, 0x75, 0x48, 0xdc, 0x78, 0x7c, 0x8e, 0x49, 0x72, 0xe9, 0xa7,
+	0x2b, 0x5b, 0x60, 0xcd, 0x0b, 0xe0, 0x41, 0xb2, 0x2c, 0xb7, 0x1f, 0xe8, 0x6b, 0x24, 0x91, 0x5f,
+	0x26, 0x09, 0x65, 0xb6, 0x0a, 0x37, 0x9a, 0x2d, 0x65, 0x70, 0x8a, 0x0b, 0x83, 0x63, 0xff, 0xa4,
+	0x41, 0xb5, 0x17, 0x24, 0xa9, 0xeb, 0xfb, 0xc3, 0xd4, 0x4d, 0x13, 0x8a, 0x82, 0x89, 0xeb, 0xf9,
+	0x33, 0x76, 0x3d, 0x03, 0x73, 0x81, 0x12, 0xde, 0x27, 0x42, 0x2e, 0x7c, 0xfe, 0xbc, 0x06, 0x16,
+	0x12, 0x0d, 0x3c, 0x0d, 0x83, 0xf4, 0xdc, 0xe7, 0xfc, 0x68, 0x60, 0x29, 0x52, 0x8f, 0x19, 0x71,
+	0x63, 0x9f, 0x53, 0x92, 0x81, 0x85, 0xc4, 0x48, 0x36, 0x4c, 0x5d, 0x9f, 0x55, 0x6e, 0x60, 0x2e,
+	0xd8, 0x06, 0xe8, 0x47, 0x5e, 0x70, 0x86, 0xc9, 0xc7, 0x4b, 0x92, 0xa4, 0x76, 0x0d, 0xaa, 0x5c,
+	0x4c, 0xa2, 0x30, 0x48, 0x88, 0xfd, 0x03, 0xd4, 0xc4, 0x83, 0x08, 0x0b, 0xb4, 0x0f, 0xff, 0x8a,
+	0x78, 0xfb, 0x1c, 0xb5, 0x59, 0xfc, 0xf5, 0x37, 0x97, 0x7a, 0x8c, 0x91, 0xb0, 0x3e, 0x50, 0xda,
+	0xc8, 0x4a, 0xb9, 0x20, 0x41, 0xc6, 0xf7, 0x54, 0xb0, 0x37, 0x61, 0x23, 0xcb, 0x25, 0xd2, 0x5f,
+	0xa9, 0x8b, 0x45, 0x56, 0xf0, 0x18, 0x0c, 0x59, 0x01, 0x85, 0x40, 0x62, 0x69, 0x9c, 0x2c, 0x84,
+	0x72, 0x40, 0x75, 0xe8, 0x25, 0xd4, 0xc6, 0xe1, 0x34, 0x72, 0x53, 0x47, 0x3e, 0x58, 0xfe, 0x9f,
+	0x1e, 0xcc, 0xe0, 0xc6, 0x42, 0x65, 0xff, 0xa2, 0x01, 0x52, 0x13, 0xf3, 0x72, 0xd0, 0x8b, 0x6b,
+	0xf4, 0x4b, 0x13, 0xeb, 0xcd, 0x3b, 0xf3, 0x90, 0x8a, 0xcf, 0x82, 0x25, 0x7a, 0x0e, 0xd9, 0xba,
+	0xb4, 0xd6, 0x99, 0xd7, 0xbf, 0x57, 0x79, 0x89, 0xd5, 0x88, 0xe7, 0xbb, 0xb5, 0x09, 0x86, 0x9c,
+	0x01, 0x7e, 0xfb, 0x47, 0x20, 0x2f, 0xea, 0x28, 0xc3, 0xa0, 0x2b, 0x97, 0xb7, 0xf7, 0xa1, 0x26,
+	0x7d, 0x44, 0xe1, 0x0d, 0x28, 0xc5, 0x6c, 0x86, 0x64, 0xcd, 0xf7, 0xe6, 0xd9, 0xd5, 0x11, 0xc3,
+	0xd2, 0x8c, 0xe2, 0xa2, 0xef, 0x25, 0xa9, 0xc4, 0xc5, 0x6b, 0xa8, 0x72, 0xf1, 0x8b, 0x03, 0x7e,
+	0x0f, 0xd5, 0x7e, 0x78, 0xe6, 0x05, 0xf2, 0x1e, 0x19, 0xe9, 0x69, 0x0a, 0xe9, 0x51, 0xea, 0x8d,
+	0xdc, 0x24, 0xf9, 0x14, 0xc6, 0x92, 0x14, 0x32, 0x99, 0x02, 0x7b, 0x1c, 0x13, 0x37, 0x25, 0x0c,
+	0xf1, 0x65, 0x2c, 0x24, 0x7b, 0x1b, 0x0c, 0x11, 0x59, 0x14, 0x97, 0xc1, 0x4b, 0x53, 0xe1, 0xd5,
+	0x00, 0x9d, 0x52, 0xd6, 0x2d, 0xfa, 0xf8, 0x07, 0x1b, 0xd1, 0xd3, 0x30, 0x0b, 0xfc, 0x7f, 0x28,
+	0x89, 0xf3, 0xbf, 0xc7, 0xbb, 0xb4, 0x40, 0xcf, 0xa0, 0x2c, 0xa8, 0x48, 0x3e, 0xb9, 0x82, 0x3d,
+	0x85, 0x43, 0x71, 0x66, 0xb6, 0x84, 0xaf, 0xdc, 0x8d, 0xf1, 0xf5, 0x0d, 0x18, 0x1e, 0x27, 0x13,
+	0x27, 0xa1, 0x6c, 0x22, 0x3e, 0x47, 0x94, 0x57, 0x51, 0xb9, 0x06, 0x57, 0x3d, 0x45, 0xb2, 0x5f,
+	0x41, 0x4d, 0x00, 0x5f, 0x36, 0xe7, 0x36, 0x17, 0xb5, 0xb7, 0x61, 0x23, 0x73, 0x17, 0x8d, 0x92,
+	0x9f, 0x6e, 0xda, 0xfc, 0xd3, 0xcd, 0x7e, 0x0b, 0x9b, 0x62, 0xbc, 0xdf, 0x91, 0x99, 0x82, 0x82,
+	0xe5, 0xa7, 0xa2, 0x1f, 0x15, 0x6c, 0x37, 0x8c, 0x9d, 0x0b, 0x32, 0x13, 0x38, 0xa8, 0x70, 0xcd,
+	0x3b, 0x32, 0xb3, 0xbf, 0x02, 0xa4, 0x46, 0x12, 0x39, 0xaf, 0x6d, 0x66, 0x6d, 0x69, 0x33, 0xdb,
+	0x1f, 0x00, 0x94, 0xd4, 0x9f, 0xb5, 0x5f, 0x82, 0xc8, 0xfa, 0x32, 0x44, 0x06, 0xa0, 0xdf, 0xaa,
+	0x86, 0xcf, 0x5c, 0xed, 0xc9, 0x0b, 0x28, 0xcb, 0xef, 0x17, 0x54, 0x86, 0x7c, 0xeb, 0x78, 0xf4,
+	0xde, 0x5c, 0x43, 0x00, 0xc5, 0x6e, 0x6f, 0xf4, 0xf6, 0x78, 0xdf, 0xd4, 0x50, 0x09, 0x72, 0xdd,
+	0xde, 0xc8, 0x5c, 0x47, 0x06, 0x54, 0x0e, 0x3b, 0xb8, 0x7d, 0x8c, 0x7b, 0xad, 0xbe, 0x99, 0x7b,
+	0xf2, 0xa7, 0x06, 0xba, 0x78, 0x06, 0xe9, 0x3d, 0x78, 0x3f, 0xe8, 0x98, 0x6b, 0xd4, 0xe3, 0xb0,
+	0x37, 0x32, 0x35, 0x54, 0x85, 0x72, 0xab, 0x7b, 0xd4, 0x77, 0xf6, 0x9c, 0x86, 0xb9, 0x8e, 0x6a,
+	0x00, 0xad, 0xa3, 0x56, 0xfb, 0x6d, 0xc7, 0x69, 0x3a, 0x0d, 0x33, 0x87, 0x4c, 0xa8, 0xb6, 0xf0,
+	0xa8, 0x37, 0x1c, 0xf5, 0xda, 0x4c, 0x93, 0xa7, 0x9a, 0xfd, 0xe1, 0x81, 0xd3, 0x74, 0xda, 0xfd,
+	0xd6, 0xf1, 0xb0, 0x63, 0x16, 0xa4, 0x66, 0x4f, 0x6a, 0x8a, 0x48, 0x87, 0x52, 0xbb, 0xdd, 0x70,
+	0x9e, 0x39, 0x0d, 0xb3, 0x44, 0x85, 0xce, 0x51, 0x9f, 0x09, 0x65, 0x2a, 0xd0, 0x64, 0x34, 0x54,
+	0x45, 0x0a, 0x34, 0x33, 0xd0, 0x82, 0x7a, 0xc3, 0xb6, 0xa9, 0xd3, 0x82, 0xfa, 0xdc, 0xe6, 0x99,
+	0x59, 0xcd, 0x24, 0x6a, 0x64, 0xd0, 0xeb, 0x1d, 0x0f, 0xfa, 0xbd, 0x76, 0x67, 0x30, 0xec, 0x98,
+	0x35, 0x1a, 0xe0, 0x50, 0x44, 0xdb, 0x78, 0xf2, 0x14, 0x74, 0xe5, 0x4f, 0x04, 0xbd, 0x6a, 0x6f,
+	0xf0, 0x86, 0x36, 0x4a, 0x87, 0xd2, 0x77, 0x2d, 0x3c, 0xe8, 0x0d, 0xba, 0xa6, 0x86, 0x2a, 0x50,
+	0xe8, 0x60, 0xfc, 0x1e, 0x9b, 0xeb, 0xcd, 0x9f, 0x0b, 0x90, 0xfb, 0x10, 0x4d, 0xd1, 0x73, 0xc8,
+	0xd3, 0xf5, 0x86, 0x94, 0x49, 0x54, 0xb6, 0x5f, 0xfd, 0xde, 0x75, 0xb5, 0x58, 0x43, 0x6b, 0xe8,
+	0x35, 0x94, 0x04, 0xe4, 0x90, 0xfa, 0x1d, 0xb1, 0xb0, 0x1a, 0xeb, 0x0f, 0x56, 0x9c, 0x64, 0x11,
+	0x06, 0xb0, 0xd1, 0x25, 0xe9, 0x81, 0x3a, 0xb4, 0x2b, 0x57, 0x80, 0x0c, 0xf6, 0x70, 0xf5, 0x61,
+	0x16, 0xef, 0x15, 0x14, 0x39, 0xd1, 0xa2, 0xfb, 0xcb, 0xd4, 0xcb, 0x43, 0x58, 0xcb, 0x07, 0x99,
+	0xfb, 0x73, 0xc8, 0x53, 0x42, 0x47, 0x0b, 0xfb, 0x30, 0x49, 0x57, 0x74, 0x42, 0xe5, 0x7d, 0x7b,
+	0x0d, 0x7d, 0x0d, 0x05, 0xc6, 0xb6, 0x48, 0x35, 0x51, 0x88, 0xbd, 0x7e, 0x7f, 0x49, 0xaf, 0x26,
+	0x65, 0x5f, 0x8d, 0x77, 0x55, 0x5a, 0x3a, 0x0d, 0x57, 0x24, 0x55, 0x69, 0xd7, 0x5e, 0x43, 0x6d,
+	0x80, 0x2e, 0x91, 0xdb, 0x59, 0x7d, 0x81, 0x45, 0xde, 0xaa, 0x3f, 0x58, 0x71, 0x92, 0x05, 0xe9,
+	0x01, 0xcc, 0x69, 0x43, 0x6d, 0xfe, 0x12, 0x2d, 0xd5, 0x1f, 0xae, 0x3e, 0x54, 0x2e, 0x52, 0xec,
+	0x92, 0x94, 0x86, 0x51, 0xc8, 0x59, 0xf1, 0xbf, 0x7b, 0x4d, 0x2b, 0x1d, 0x4f, 0x8a, 0xec, 0xbf,
+	0xf3, 0xde, 0x5f, 0x03, 0x00, 0x53, 0x60, 0xc1, 0x62, 0x48, 0x0f, 0x00, 0x00,
 }
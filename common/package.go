@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	msg "qpm.io/common/messages"
+	"qpm.io/common/semver"
 	"qpm.io/qpm/core"
 	"regexp"
 	"strings"
@@ -23,7 +24,6 @@ const (
 
 var (
 	regexPackageName = regexp.MustCompile("^[a-zA-Z]{2,}\\.[a-zA-Z0-9][a-zA-Z0-9\\-]{0,61}[a-zA-Z0-9]?(\\.[a-zA-Z0-9][a-zA-Z0-9\\-]{0,61}[a-zA-Z0-9]?)+$")
-	regexVersion     = regexp.MustCompile("[0-9].[0-9].[0-9]*")
 	regexAuthorName  = regexp.MustCompile("^[\\p{L}\\s'.-]+$")
 	regexAuthorEmail = regexp.MustCompile(".+@.+\\..+")
 	regexGitSha1     = regexp.MustCompile("^[a-fA-F0-9]{8,}$")
@@ -42,26 +42,36 @@ func packageName(release string) string {
 	return strings.Split(release, "@")[0]
 }
 
+// ParseDependency splits a dependency signature of the form "package@range" into the
+// package name and the version range. The range is empty if none was given.
+func ParseDependency(signature string) (name string, constraint string) {
+	parts := strings.SplitN(signature, "@", 2)
+	name = strings.ToLower(strings.TrimSpace(parts[0]))
+	if len(parts) > 1 {
+		constraint = strings.ToLower(strings.TrimSpace(parts[1]))
+	}
+	return name, constraint
+}
+
 type DependencyList map[string]string
 
 // Creates a new DependencyList (which is really a map) which takes a list of package
-// names of the form "package@version" and produces a map of "package => "version".
+// names of the form "package@range" and produces a map of "package => "range".
 // Passing in multiple versions of the same package will overwrite with the last one.
 func NewDependencyList(packages []string) DependencyList {
 	deps := DependencyList{}
 	for _, dep := range packages {
-		parts := strings.Split(dep, "@")
-		pName := strings.ToLower(parts[0])
-
-		if len(parts) > 1 {
-			deps[pName] = strings.ToLower(parts[1])
-		} else {
-			deps[pName] = ""
-		}
+		pName, constraint := ParseDependency(dep)
+		deps[pName] = constraint
 	}
 	return deps
 }
 
+// Constraint returns the parsed version range for the given package.
+func (dl DependencyList) Constraint(name string) (*semver.Constraint, error) {
+	return semver.ParseConstraint(dl[name])
+}
+
 func NewPackage() *msg.Package {
 	return &msg.Package{
 		Name:        "",
@@ -127,16 +137,40 @@ func LoadPackages(vendorDir string) (map[string]*PackageWrapper, error) {
 		return packageMap, err
 	}
 
+	// The vendor directories of the packages themselves
+	nested := make(map[string]bool)
+
 	err := filepath.Walk(vendorDir, func(path string, info os.FileInfo, err error) error {
-		if !info.IsDir() && filepath.Base(path) == core.PackageFile {
-			pkg, err := LoadPackage(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() || path == vendorDir {
+			return nil
+		}
+		// Skip VCS metadata
+		if strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		// Skip any packages vendored by the packages themselves
+		if nested[path] {
+			return filepath.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(path, core.PackageFile)); err == nil {
+			pkg, err := LoadPackage(path)
 			if err != nil {
 				return err
 			}
+			// Package files in the examples or tests of a package are not installed
+			// packages, so neither is anything below them
+			if pkg.Name == "" || core.PackageDir(vendorDir, pkg.Name) != path {
+				return filepath.SkipDir
+			}
+			// Keep walking after finding a package since other packages can be nested
+			// inside it, eg: com.foo.bar inside com.foo
 			packageMap[pkg.Name] = pkg
-
-			// found what we're looking for so skip the rest
-			return filepath.SkipDir
+			nested[filepath.Join(path, pkg.VendorPath())] = true
 		}
 		return nil
 	})
@@ -190,7 +224,7 @@ func (pw PackageWrapper) Validate() error {
 		return fmt.Errorf(ERR_REQUIRED_FIELD, "version")
 	} else {
 		// Validate version label
-		if !regexVersion.MatchString(pw.Version.Label) {
+		if _, err := semver.Parse(pw.Version.Label); err != nil {
 			return fmt.Errorf(ERR_FORMATTED_FIELD, "version label")
 		}
 		// Validate version revision
@@ -210,6 +244,14 @@ func (pw PackageWrapper) Validate() error {
 			return fmt.Errorf(ERR_FORMATTED_FIELD, "author email")
 		}
 	}
+	for _, d := range pw.Dependencies {
+		// Validate dependency version ranges
+		if _, constraint := ParseDependency(d); constraint != "" {
+			if _, err := semver.ParseConstraint(constraint); err != nil {
+				return fmt.Errorf(ERR_FORMATTED_FIELD, "dependency "+d)
+			}
+		}
+	}
 
 	return nil
 }
@@ -225,6 +267,15 @@ func (pw PackageWrapper) PriFile() string {
 	return dotUnderscore(pw.Package.Name) + ".pri"
 }
 
+// VendorPath returns the directory, relative to the package, that the package's own
+// dependencies are installed in.
+func (pw PackageWrapper) VendorPath() string {
+	if pw.Package.VendorDir != "" {
+		return filepath.FromSlash(pw.Package.VendorDir)
+	}
+	return core.Vendor
+}
+
 func (pw PackageWrapper) QrcFile() string {
 	return dotUnderscore(pw.Package.Name) + ".qrc"
 }
@@ -233,6 +284,15 @@ func (pw PackageWrapper) QrcPrefix() string {
 	return dotSlash(pw.Package.Name)
 }
 
+// VersionLabel returns the version of the package, or an empty string if the package file
+// has no version.
+func (pw PackageWrapper) VersionLabel() string {
+	if pw.Version == nil {
+		return ""
+	}
+	return pw.Version.Label
+}
+
 func (pw PackageWrapper) GetDependencySignature() string {
 	return strings.Join([]string{pw.Name, pw.Version.Label}, "@")
 }
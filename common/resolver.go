@@ -0,0 +1,347 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	msg "qpm.io/common/messages"
+	"qpm.io/common/semver"
+)
+
+// Resolution gives up if the selected versions keep changing after this many passes.
+const maxResolvePasses = 100
+
+// PackageSource provides the registry information needed to resolve dependencies.
+type PackageSource interface {
+	// Info returns the registry entry for a package, including all of its published versions.
+	Info(name string) (*msg.InfoResponse, error)
+	// Dependencies returns the dependency signatures declared by a specific release. If the
+	// source does not know them, it may return an estimate and report that they are not
+	// exact.
+	Dependencies(info *msg.InfoResponse, version *msg.Package_Version) (deps []string, exact bool, err error)
+}
+
+// Requirement is a version range that one package places on another.
+type Requirement struct {
+	Constraint string
+	// Chain of "package@version" signatures from the root to the package that declared
+	// the requirement.
+	Chain []string
+	// Assumed is set if a package in the chain only has estimated dependencies, so that
+	// the requirement may not exist.
+	Assumed bool
+}
+
+// ConflictError is returned when no single version of a package satisfies every package
+// that depends on it.
+type ConflictError struct {
+	Name         string
+	Requirements []Requirement
+}
+
+func (e *ConflictError) Error() string {
+	lines := []string{fmt.Sprintf("no version of %s satisfies all of its dependents:", e.Name)}
+	for _, r := range e.Requirements {
+		line := strings.Join(r.Chain, " -> ") + " requires " + e.Name
+		if r.Constraint != "" {
+			line += "@" + r.Constraint
+		}
+		if r.Assumed {
+			line += " (assumed)"
+		}
+		lines = append(lines, "\t"+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Assumed reports whether the conflict depends on estimated dependencies, in which case
+// the packages may not conflict at all.
+func (e *ConflictError) Assumed() bool {
+	for _, r := range e.Requirements {
+		if r.Assumed {
+			return true
+		}
+	}
+	return false
+}
+
+// Satisfies reports whether the version label is allowed by the given version range. Labels
+// that are not valid semantic versions only match an identical range.
+func Satisfies(label string, constraint string) bool {
+	if label == constraint {
+		return true
+	}
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.Parse(label)
+	if err != nil {
+		return constraint == ""
+	}
+	return c.Check(v)
+}
+
+// LatestVersion returns the newest of the published versions that satisfies all of the given
+// version ranges, or nil if there is no such version.
+func LatestVersion(versions []*msg.VersionInfo, constraints ...string) *msg.Package_Version {
+	var latest *msg.Package_Version
+	var latestSemver *semver.Version
+
+	// pre-releases are only considered if a range asks for them
+	if len(constraints) == 0 {
+		constraints = []string{""}
+	}
+
+	for _, vi := range versions {
+		v, err := semver.Parse(vi.Version.Label)
+		if err != nil {
+			continue
+		}
+		acceptable := true
+		for _, c := range constraints {
+			if !Satisfies(vi.Version.Label, c) {
+				acceptable = false
+				break
+			}
+		}
+		if acceptable && (latestSemver == nil || latestSemver.LessThan(v)) {
+			latest = vi.Version
+			latestSemver = v
+		}
+	}
+
+	return latest
+}
+
+type candidate struct {
+	dependency   *msg.Dependency
+	dependencies []string
+	// exact is false if dependencies are an estimate.
+	exact bool
+}
+
+// Resolution is the set of packages chosen by the Resolver.
+type Resolution struct {
+	// Dependencies contains one release of every package in the graph, sorted by name.
+	Dependencies []*msg.Dependency
+	requires     map[string][]string
+}
+
+// Closure returns the named packages along with everything they depend on.
+func (r Resolution) Closure(names []string) []*msg.Dependency {
+	needed := make(map[string]bool)
+
+	var visit func(name string)
+	visit = func(name string) {
+		if needed[name] {
+			return
+		}
+		needed[name] = true
+		for _, d := range r.requires[name] {
+			visit(d)
+		}
+	}
+	for _, n := range names {
+		visit(n)
+	}
+
+	deps := []*msg.Dependency{}
+	for _, d := range r.Dependencies {
+		if needed[d.Name] {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// Resolver picks one version of every package in a dependency graph so that all of the
+// version ranges declared along the way are satisfied.
+type Resolver struct {
+	source PackageSource
+	hints  map[string]string
+	infos  map[string]*msg.InfoResponse
+}
+
+func NewResolver(source PackageSource) *Resolver {
+	return &Resolver{
+		source: source,
+		hints:  make(map[string]string),
+		infos:  make(map[string]*msg.InfoResponse),
+	}
+}
+
+// Hint marks a version as preferred for a package, for example the one suggested by the
+// server. It is only used if it satisfies every requirement.
+func (r *Resolver) Hint(name string, label string) {
+	r.hints[name] = label
+}
+
+// Resolve builds the dependency graph starting from the given dependency signatures. The
+// root is only used to describe the requirements when reporting a conflict.
+func (r *Resolver) Resolve(root string, dependencies []string) (*Resolution, error) {
+
+	selected := make(map[string]*candidate)
+
+	for pass := 0; pass < maxResolvePasses; pass++ {
+
+		// Gather the requirements placed on each package by the current selection
+		requirements := make(map[string][]Requirement)
+		var order []string
+		visited := make(map[string]bool)
+
+		var walk func(deps []string, chain []string, assumed bool)
+		walk = func(deps []string, chain []string, assumed bool) {
+			for _, d := range deps {
+				name, constraint := ParseDependency(d)
+				if _, seen := requirements[name]; !seen {
+					order = append(order, name)
+				}
+				requirements[name] = append(requirements[name], Requirement{
+					Constraint: constraint,
+					Chain:      chain,
+					Assumed:    assumed,
+				})
+
+				if c, ok := selected[name]; ok && !visited[name] {
+					visited[name] = true
+					signature := name + "@" + c.dependency.Version.Label
+					walk(c.dependencies, append(chain[:len(chain):len(chain)], signature), assumed || !c.exact)
+				}
+			}
+		}
+		walk(dependencies, []string{root}, false)
+
+		// Pick the best version for each package given those requirements
+		changed := false
+		for _, name := range order {
+			c, err := r.choose(name, requirements[name])
+			if err != nil {
+				return nil, err
+			}
+			if s, ok := selected[name]; !ok || s.dependency.Version.Label != c.dependency.Version.Label {
+				selected[name] = c
+				changed = true
+			}
+		}
+
+		// Drop packages that are no longer needed by anything
+		for name := range selected {
+			if _, ok := requirements[name]; !ok {
+				delete(selected, name)
+				changed = true
+			}
+		}
+
+		if !changed {
+			return r.resolution(selected), nil
+		}
+	}
+
+	return nil, fmt.Errorf("dependency resolution did not settle after %d passes", maxResolvePasses)
+}
+
+func (r *Resolver) resolution(selected map[string]*candidate) *Resolution {
+	res := &Resolution{
+		Dependencies: []*msg.Dependency{},
+		requires:     make(map[string][]string),
+	}
+
+	names := []string{}
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		c := selected[name]
+		res.Dependencies = append(res.Dependencies, c.dependency)
+		for _, d := range c.dependencies {
+			depName, _ := ParseDependency(d)
+			res.requires[name] = append(res.requires[name], depName)
+		}
+	}
+	return res
+}
+
+func (r *Resolver) info(name string) (*msg.InfoResponse, error) {
+	if info, ok := r.infos[name]; ok {
+		return info, nil
+	}
+	info, err := r.source.Info(name)
+	if err != nil {
+		return nil, err
+	}
+	r.infos[name] = info
+	return info, nil
+}
+
+// choose returns the hinted version if it is acceptable, otherwise the newest version that
+// satisfies all of the requirements.
+func (r *Resolver) choose(name string, requirements []Requirement) (*candidate, error) {
+	info, err := r.info(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.Package == nil {
+		return nil, fmt.Errorf("package %s was not found", name)
+	}
+
+	versions := []*msg.Package_Version{}
+	for _, v := range info.Versions {
+		versions = append(versions, v.Version)
+	}
+	if len(versions) == 0 && info.Package.Version != nil {
+		versions = append(versions, info.Package.Version)
+	}
+
+	var best *msg.Package_Version
+	var bestSemver *semver.Version
+
+	for _, v := range versions {
+		acceptable := true
+		for _, req := range requirements {
+			if !Satisfies(v.Label, req.Constraint) {
+				acceptable = false
+				break
+			}
+		}
+		if !acceptable {
+			continue
+		}
+
+		if hint, ok := r.hints[name]; ok && hint == v.Label {
+			best = v
+			break
+		}
+
+		sv, err := semver.Parse(v.Label)
+		if best == nil || (err == nil && (bestSemver == nil || bestSemver.LessThan(sv))) {
+			best = v
+			bestSemver = sv
+		}
+	}
+
+	if best == nil {
+		return nil, &ConflictError{Name: name, Requirements: requirements}
+	}
+
+	deps, exact, err := r.source.Dependencies(info, best)
+	if err != nil {
+		return nil, err
+	}
+
+	return &candidate{
+		dependency: &msg.Dependency{
+			Name:       name,
+			Repository: info.Package.Repository,
+			Version:    best,
+		},
+		dependencies: deps,
+		exact:        exact,
+	}, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	msg "qpm.io/common/messages"
+)
+
+type release struct {
+	label        string
+	dependencies []string
+}
+
+// testSource is a PackageSource with the given releases of each package, newest last. If
+// latestOnly is set, it only knows the dependencies of the newest release and assumes
+// the same for the others, like the client does.
+type testSource struct {
+	packages   map[string][]release
+	latestOnly bool
+}
+
+func (s *testSource) Info(name string) (*msg.InfoResponse, error) {
+	releases, ok := s.packages[name]
+	if !ok {
+		return nil, fmt.Errorf("package %s does not exist", name)
+	}
+
+	latest := releases[len(releases)-1]
+	info := &msg.InfoResponse{
+		Package: &msg.Package{
+			Name:         name,
+			Version:      &msg.Package_Version{Label: latest.label},
+			Dependencies: latest.dependencies,
+		},
+	}
+	for _, r := range releases {
+		info.Versions = append(info.Versions, &msg.VersionInfo{Version: &msg.Package_Version{Label: r.label}})
+	}
+	return info, nil
+}
+
+func (s *testSource) Dependencies(info *msg.InfoResponse, version *msg.Package_Version) ([]string, bool, error) {
+	if s.latestOnly {
+		return info.Package.Dependencies, info.Package.Version.Label == version.Label, nil
+	}
+	for _, r := range s.packages[info.Package.Name] {
+		if r.label == version.Label {
+			return r.dependencies, true, nil
+		}
+	}
+	return nil, false, fmt.Errorf("version %s of %s does not exist", version.Label, info.Package.Name)
+}
+
+// labels returns the resolved packages as name@version signatures.
+func labels(resolution *Resolution) []string {
+	var signatures []string
+	for _, d := range resolution.Dependencies {
+		signatures = append(signatures, d.Name+"@"+d.Version.Label)
+	}
+	return signatures
+}
+
+func TestResolvePicksNewestAcceptableVersions(t *testing.T) {
+	source := &testSource{packages: map[string][]release{
+		"io.qpm.alpha": {{"1.0.0", nil}, {"1.1.0", nil}, {"2.0.0", nil}},
+		"io.qpm.beta":  {{"1.0.0", []string{"io.qpm.alpha@^1.0"}}},
+	}}
+
+	resolution, err := NewResolver(source).Resolve("app", []string{"io.qpm.beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"io.qpm.alpha@1.1.0", "io.qpm.beta@1.0.0"}; !reflect.DeepEqual(labels(resolution), expected) {
+		t.Errorf("expected %v, got %v", expected, labels(resolution))
+	}
+}
+
+func TestResolvePrefersAcceptableHints(t *testing.T) {
+	source := &testSource{packages: map[string][]release{
+		"io.qpm.alpha": {{"1.0.0", nil}, {"1.1.0", nil}, {"2.0.0", nil}},
+	}}
+
+	resolver := NewResolver(source)
+	resolver.Hint("io.qpm.alpha", "1.0.0")
+	resolution, err := resolver.Resolve("app", []string{"io.qpm.alpha@^1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"io.qpm.alpha@1.0.0"}; !reflect.DeepEqual(labels(resolution), expected) {
+		t.Errorf("expected the hint %v, got %v", expected, labels(resolution))
+	}
+
+	resolver.Hint("io.qpm.alpha", "2.0.0")
+	resolution, err = resolver.Resolve("app", []string{"io.qpm.alpha@^1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"io.qpm.alpha@1.1.0"}; !reflect.DeepEqual(labels(resolution), expected) {
+		t.Errorf("expected the unacceptable hint to be ignored for %v, got %v", expected, labels(resolution))
+	}
+}
+
+func TestResolveReportsConflictChains(t *testing.T) {
+	source := &testSource{packages: map[string][]release{
+		"io.qpm.alpha": {{"1.0.0", nil}, {"2.0.0", nil}},
+		"io.qpm.beta":  {{"1.0.0", []string{"io.qpm.gamma@^1.0"}}},
+		"io.qpm.gamma": {{"1.0.0", []string{"io.qpm.alpha@^1.0"}}},
+		"io.qpm.delta": {{"1.0.0", []string{"io.qpm.alpha@^2.0"}}},
+	}}
+
+	_, err := NewResolver(source).Resolve("app", []string{"io.qpm.beta", "io.qpm.delta"})
+	conflict, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if conflict.Name != "io.qpm.alpha" || conflict.Assumed() {
+		t.Errorf("expected a conflict on io.qpm.alpha that is not assumed, got %+v", conflict)
+	}
+
+	expected := strings.Join([]string{
+		"no version of io.qpm.alpha satisfies all of its dependents:",
+		"\tapp -> io.qpm.beta@1.0.0 -> io.qpm.gamma@1.0.0 requires io.qpm.alpha@^1.0",
+		"\tapp -> io.qpm.delta@1.0.0 requires io.qpm.alpha@^2.0",
+	}, "\n")
+	if conflict.Error() != expected {
+		t.Errorf("expected the message\n%s\ngot\n%s", expected, conflict.Error())
+	}
+}
+
+func TestResolveMarksConflictsFromAssumedDependencies(t *testing.T) {
+	// Only the newest release of alpha needs gamma 2, but the source assumes that the
+	// release picked here does too
+	packages := map[string][]release{
+		"io.qpm.alpha": {{"1.0.0", []string{"io.qpm.gamma@^1.0"}}, {"1.1.0", []string{"io.qpm.gamma@^2.0"}}},
+		"io.qpm.gamma": {{"1.0.0", nil}, {"2.0.0", nil}},
+	}
+	dependencies := []string{"io.qpm.alpha@~1.0.0", "io.qpm.gamma@^1.0"}
+
+	if _, err := NewResolver(&testSource{packages: packages}).Resolve("app", dependencies); err != nil {
+		t.Fatalf("expected the exact dependencies to resolve, got %v", err)
+	}
+
+	_, err := NewResolver(&testSource{packages: packages, latestOnly: true}).Resolve("app", dependencies)
+	conflict, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if !conflict.Assumed() {
+		t.Errorf("expected the conflict to be assumed: %v", conflict)
+	}
+	if !strings.Contains(conflict.Error(), "app -> io.qpm.alpha@1.0.0 requires io.qpm.gamma@^2.0 (assumed)") {
+		t.Errorf("expected the assumed requirement to be marked:\n%v", conflict)
+	}
+}
+
+func TestResolutionClosure(t *testing.T) {
+	source := &testSource{packages: map[string][]release{
+		"io.qpm.alpha": {{"1.0.0", nil}},
+		"io.qpm.beta":  {{"1.0.0", []string{"io.qpm.alpha"}}},
+		"io.qpm.gamma": {{"1.0.0", nil}},
+	}}
+
+	resolution, err := NewResolver(source).Resolve("app", []string{"io.qpm.beta", "io.qpm.gamma"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, d := range resolution.Closure([]string{"io.qpm.beta"}) {
+		names = append(names, d.Name)
+	}
+	if expected := []string{"io.qpm.alpha", "io.qpm.beta"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	regexPartial  = regexp.MustCompile("^v?([0-9]+|[xX*])(?:\\.([0-9]+|[xX*]))?(?:\\.([0-9]+|[xX*]))?(?:-([0-9A-Za-z-]+(?:\\.[0-9A-Za-z-]+)*))?(?:\\+[0-9A-Za-z-]+(?:\\.[0-9A-Za-z-]+)*)?$")
+	regexOperator = regexp.MustCompile("^(\\^|~>?|>=|<=|>|<|=)?(.*)$")
+)
+
+type operator int
+
+const (
+	opEqual operator = iota
+	opGreater
+	opGreaterEqual
+	opLess
+	opLessEqual
+)
+
+type comparator struct {
+	op      operator
+	version *Version
+}
+
+func (c comparator) check(v *Version) bool {
+	switch c.op {
+	case opEqual:
+		return v.Equal(c.version)
+	case opGreater:
+		return c.version.LessThan(v)
+	case opGreaterEqual:
+		return !v.LessThan(c.version)
+	case opLess:
+		return v.LessThan(c.version)
+	case opLessEqual:
+		return !c.version.LessThan(v)
+	}
+	return false
+}
+
+// Constraint is a version range such as "^1.2", "~1.2.3", ">=1.0 <2.0" or "1.x". Space
+// separated comparators must all be satisfied, and alternatives can be given with "||".
+// An exact version such as "1.2.3" only matches itself and an empty constraint matches
+// any release.
+type Constraint struct {
+	original string
+	sets     [][]comparator
+}
+
+// ParseConstraint parses a version range.
+func ParseConstraint(constraint string) (*Constraint, error) {
+	c := &Constraint{original: strings.TrimSpace(constraint)}
+
+	for _, alternative := range strings.Split(c.original, "||") {
+		set := []comparator{}
+
+		fields := strings.Fields(alternative)
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+
+			// allow whitespace between the operator and the version, eg: ">= 1.0"
+			if regexOperator.FindStringSubmatch(field)[2] == "" && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+
+			comparators, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not a valid version range: %v", constraint, err)
+			}
+			set = append(set, comparators...)
+		}
+
+		c.sets = append(c.sets, set)
+	}
+
+	return c, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics if the range cannot be parsed.
+func MustParseConstraint(constraint string) *Constraint {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (c Constraint) String() string {
+	return c.original
+}
+
+// Check reports whether the version satisfies the constraint. Pre-releases are only matched
+// by comparators that mention a pre-release of the same MAJOR.MINOR.PATCH, so that "^1.0"
+// does not unexpectedly select "2.0.0-beta".
+func (c Constraint) Check(v *Version) bool {
+	for _, set := range c.sets {
+		if checkSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkSet(set []comparator, v *Version) bool {
+	for _, comp := range set {
+		if !comp.check(v) {
+			return false
+		}
+	}
+
+	if !v.IsPreRelease() {
+		return true
+	}
+
+	for _, comp := range set {
+		cv := comp.version
+		if cv.IsPreRelease() && cv.Major == v.Major && cv.Minor == v.Minor && cv.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// Latest returns the highest of the given versions that satisfies the constraint, or nil if
+// none of them do.
+func (c Constraint) Latest(versions []*Version) *Version {
+	var latest *Version
+	for _, v := range versions {
+		if c.Check(v) && (latest == nil || latest.LessThan(v)) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// Exact returns the version if the constraint only matches a single version, otherwise nil.
+func (c Constraint) Exact() *Version {
+	if len(c.sets) == 1 && len(c.sets[0]) == 1 && c.sets[0][0].op == opEqual {
+		return c.sets[0][0].version
+	}
+	return nil
+}
+
+// parseComparator expands a single operator and (possibly partial) version into one or
+// more primitive comparators.
+func parseComparator(s string) ([]comparator, error) {
+	m := regexOperator.FindStringSubmatch(s)
+	op, version := m[1], m[2]
+
+	v, parts, err := parsePartial(version)
+	if err != nil {
+		return nil, err
+	}
+
+	wildcard := []comparator{{opGreaterEqual, &Version{}}}
+	none := []comparator{{opLess, &Version{}}}
+
+	// the first version after the specified parts, eg: 1.2 => 1.3.0
+	next := func() *Version {
+		switch parts {
+		case 1:
+			return &Version{Major: v.Major + 1}
+		case 2:
+			return &Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+
+	switch op {
+	case "", "=":
+		if parts == 0 {
+			return wildcard, nil
+		} else if parts == 3 {
+			return []comparator{{opEqual, v}}, nil
+		}
+		return []comparator{{opGreaterEqual, v}, {opLess, next()}}, nil
+
+	case "^":
+		if parts == 0 {
+			return wildcard, nil
+		}
+		var upper *Version
+		if v.Major > 0 || parts == 1 {
+			upper = &Version{Major: v.Major + 1}
+		} else if v.Minor > 0 || parts == 2 {
+			upper = &Version{Minor: v.Minor + 1}
+		} else {
+			upper = &Version{Patch: v.Patch + 1}
+		}
+		return []comparator{{opGreaterEqual, v}, {opLess, upper}}, nil
+
+	case "~", "~>":
+		if parts == 0 {
+			return wildcard, nil
+		} else if parts == 1 {
+			return []comparator{{opGreaterEqual, v}, {opLess, &Version{Major: v.Major + 1}}}, nil
+		}
+		return []comparator{{opGreaterEqual, v}, {opLess, &Version{Major: v.Major, Minor: v.Minor + 1}}}, nil
+
+	case ">":
+		if parts == 0 {
+			return none, nil
+		} else if parts == 3 {
+			return []comparator{{opGreater, v}}, nil
+		}
+		return []comparator{{opGreaterEqual, next()}}, nil
+
+	case ">=":
+		return []comparator{{opGreaterEqual, v}}, nil
+
+	case "<":
+		if parts == 0 {
+			return none, nil
+		}
+		return []comparator{{opLess, v}}, nil
+
+	case "<=":
+		if parts == 0 {
+			return wildcard, nil
+		} else if parts == 3 {
+			return []comparator{{opLessEqual, v}}, nil
+		}
+		return []comparator{{opLess, next()}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// parsePartial parses a version where trailing parts may be missing or wildcards and
+// returns the number of parts that were specified.
+func parsePartial(s string) (*Version, int, error) {
+	m := regexPartial.FindStringSubmatch(s)
+	if m == nil {
+		return nil, 0, fmt.Errorf("%s is not a valid version", s)
+	}
+
+	v := &Version{}
+	numbers := []*int64{&v.Major, &v.Minor, &v.Patch}
+
+	parts := 0
+	for i, n := range m[1:4] {
+		if n == "" || n == "x" || n == "X" || n == "*" {
+			break
+		}
+		value, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		*numbers[i] = value
+		parts++
+	}
+
+	if m[4] != "" {
+		if parts < 3 {
+			return nil, 0, fmt.Errorf("%s has a pre-release but no patch version", s)
+		}
+		v.PreRelease = strings.Split(m[4], ".")
+	}
+
+	return v, parts, nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+// Package semver implements parsing and comparison of semantic versions as described at
+// http://semver.org, along with the version ranges used in package dependencies.
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	regexVersion    = regexp.MustCompile("^v?([0-9]+)\\.([0-9]+)\\.([0-9]+)(?:-([0-9A-Za-z-]+(?:\\.[0-9A-Za-z-]+)*))?(?:\\+([0-9A-Za-z-]+(?:\\.[0-9A-Za-z-]+)*))?$")
+	regexIdentifier = regexp.MustCompile("^[0-9]+$")
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Major      int64
+	Minor      int64
+	Patch      int64
+	PreRelease []string
+	Build      string
+}
+
+// Parse parses a version of the form MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]. A leading "v"
+// is accepted but all three numbers are required.
+func Parse(version string) (*Version, error) {
+	m := regexVersion.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return nil, fmt.Errorf("%s is not a valid semantic version", version)
+	}
+
+	v := &Version{Build: m[5]}
+
+	var err error
+	if v.Major, err = parseNumber(m[1]); err != nil {
+		return nil, err
+	}
+	if v.Minor, err = parseNumber(m[2]); err != nil {
+		return nil, err
+	}
+	if v.Patch, err = parseNumber(m[3]); err != nil {
+		return nil, err
+	}
+
+	if m[4] != "" {
+		v.PreRelease = strings.Split(m[4], ".")
+		for _, id := range v.PreRelease {
+			if len(id) > 1 && id[0] == '0' && regexIdentifier.MatchString(id) {
+				return nil, fmt.Errorf("%s has a pre-release identifier with a leading zero", version)
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// MustParse is like Parse but panics if the version cannot be parsed.
+func MustParse(version string) *Version {
+	v, err := Parse(version)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func parseNumber(s string) (int64, error) {
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%s has a leading zero", s)
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.PreRelease) > 0 {
+		s += "-" + strings.Join(v.PreRelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPreRelease reports whether the version has pre-release identifiers.
+func (v Version) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is lower than, equal to or higher
+// than o. Build metadata is ignored as required by the specification.
+func (v Version) Compare(o *Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A version without pre-release identifiers has a higher precedence
+	if len(v.PreRelease) == 0 || len(o.PreRelease) == 0 {
+		return compareInt(int64(len(o.PreRelease)), int64(len(v.PreRelease)))
+	}
+
+	for i := 0; i < len(v.PreRelease) && i < len(o.PreRelease); i++ {
+		if c := compareIdentifier(v.PreRelease[i], o.PreRelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(int64(len(v.PreRelease)), int64(len(o.PreRelease)))
+}
+
+func (v Version) LessThan(o *Version) bool {
+	return v.Compare(o) < 0
+}
+
+func (v Version) Equal(o *Version) bool {
+	return v.Compare(o) == 0
+}
+
+func compareInt(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// Numeric identifiers are compared numerically and always have a lower precedence than
+// alphanumeric identifiers which are compared lexically.
+func compareIdentifier(a, b string) int {
+	aNum := regexIdentifier.MatchString(a)
+	bNum := regexIdentifier.MatchString(b)
+
+	switch {
+	case aNum && bNum:
+		x, _ := strconv.ParseInt(a, 10, 64)
+		y, _ := strconv.ParseInt(b, 10, 64)
+		return compareInt(x, y)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Versions implements sort.Interface to sort versions in ascending order.
+type Versions []*Version
+
+func (vs Versions) Len() int           { return len(vs) }
+func (vs Versions) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+func (vs Versions) Less(i, j int) bool { return vs[i].LessThan(vs[j]) }
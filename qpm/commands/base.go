@@ -4,6 +4,9 @@
 package commands
 
 import (
+	"path/filepath"
+
+	"qpm.io/common"
 	"qpm.io/qpm/core"
 )
 
@@ -30,3 +33,22 @@ func (bc BaseCommand) Error(err error) {
 func (bc BaseCommand) Fatal(msg string) {
 	bc.Ctx.Log.Fatal(msg)
 }
+
+// resolveVendorDir returns the absolute path of the directory that packages are installed in.
+// It is given by the --vendor-dir flag, the package file or the configuration, in that order.
+func (bc BaseCommand) resolveVendorDir(flagValue string, pkg *common.PackageWrapper) string {
+	dir := flagValue
+	if dir == "" && pkg != nil && pkg.Package != nil && pkg.Package.VendorDir != "" {
+		dir = pkg.VendorPath()
+		if pkg.FilePath != "" {
+			dir = filepath.Join(pkg.RootDir(), dir)
+		}
+	}
+	if dir == "" {
+		dir = bc.Ctx.VendorDir()
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
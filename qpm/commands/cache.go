@@ -0,0 +1,112 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"qpm.io/qpm/core"
+	"qpm.io/qpm/vcs"
+)
+
+type CacheCommand struct {
+	BaseCommand
+	fs    *flag.FlagSet
+	cache *vcs.Cache
+}
+
+func NewCacheCommand(ctx core.Context) *CacheCommand {
+	return &CacheCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (c CacheCommand) Description() string {
+	return "Manages the local package cache"
+}
+
+func (c *CacheCommand) RegisterFlags(flags *flag.FlagSet) {
+	c.fs = flags
+}
+
+func (c *CacheCommand) Run() error {
+
+	c.cache = vcs.NewCache(c.Ctx.CacheDir())
+
+	switch c.fs.Arg(0) {
+	case "list":
+		return c.list()
+	case "clean":
+		return c.clean()
+	case "verify":
+		return c.verify()
+	}
+
+	err := fmt.Errorf("Unknown cache command %q, expected list, clean or verify", c.fs.Arg(0))
+	c.Error(err)
+	return err
+}
+
+func (c *CacheCommand) list() error {
+	entries, err := c.cache.Entries()
+	if err != nil {
+		c.Error(err)
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("The cache in %s is empty.\n", c.cache.Dir)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "Package\tVersion\tRevision\tRepository\t")
+	for _, e := range entries {
+		revision := e.Revision
+		if len(revision) > 8 {
+			revision = revision[:8]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", e.Name, e.Version, revision, e.Url)
+	}
+	w.Flush()
+
+	return nil
+}
+
+func (c *CacheCommand) clean() error {
+	fmt.Println("Removing the packages cached in", c.cache.Dir)
+	if err := c.cache.Clean(); err != nil {
+		c.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (c *CacheCommand) verify() error {
+	entries, err := c.cache.Entries()
+	if err != nil {
+		c.Error(err)
+		return err
+	}
+
+	failed := 0
+	for _, e := range entries {
+		if err := e.Verify(); err != nil {
+			c.Error(err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d cache entries are corrupt, run 'qpm cache clean' to remove them", failed)
+	}
+
+	fmt.Printf("Verified %d cache entries.\n", len(entries))
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+var (
+	vendorCMake = template.Must(template.New("vendorCMake").Parse(`# Generated by qpm, do not edit.
+#
+# include(vendor/vendor.cmake)
+# qpm_link_packages(myapp)
+
+set(QPM_VENDOR_DIR "${CMAKE_CURRENT_LIST_DIR}")
+
+# Lets Qt Creator find the QML modules in the packages
+list(APPEND QML_IMPORT_PATH "${QPM_VENDOR_DIR}")
+list(REMOVE_DUPLICATES QML_IMPORT_PATH)
+set(QML_IMPORT_PATH "${QML_IMPORT_PATH}" CACHE STRING "QML import paths" FORCE)
+
+set(QPM_SOURCES{{range .Sources}}
+    "${QPM_VENDOR_DIR}/{{.}}"{{end}}
+)
+
+set(QPM_RESOURCES{{range .Resources}}
+    "${QPM_VENDOR_DIR}/{{.}}"{{end}}
+)
+{{range .Includes}}
+set(QPM_PACKAGE_DIR "${QPM_VENDOR_DIR}/{{.Dir}}")
+include("${QPM_VENDOR_DIR}/{{.File}}"){{end}}
+unset(QPM_PACKAGE_DIR)
+
+# Adds the sources and resources of every package to the target
+function(qpm_link_packages target)
+    target_sources(${target} PRIVATE ${QPM_SOURCES})
+    target_include_directories(${target} PRIVATE "${QPM_VENDOR_DIR}")
+    target_compile_definitions(${target} PRIVATE
+        "QPM_INIT(E)=E.addImportPath(QStringLiteral(\"qrc:/\"))\;"
+        QPM_USE_NS
+    )
+    if(QPM_RESOURCES)
+        qt_add_resources(qpm_resource_sources ${QPM_RESOURCES})
+        target_sources(${target} PRIVATE ${qpm_resource_sources})
+    endif()
+endfunction()
+`))
+)
+
+// sourceExtensions are the files that are added to the build as sources.
+var sourceExtensions = map[string]bool{
+	".c":   true,
+	".cc":  true,
+	".cpp": true,
+	".cxx": true,
+	".h":   true,
+	".hh":  true,
+	".hpp": true,
+	".hxx": true,
+}
+
+// ignoredDirs hold files that are not part of the package itself, such as its tests and
+// examples which often have their own main function.
+var ignoredDirs = map[string]bool{
+	"autotests":  true,
+	"benchmark":  true,
+	"benchmarks": true,
+	"example":    true,
+	"examples":   true,
+	"test":       true,
+	"tests":      true,
+}
+
+// relPath returns path relative to vendorDir with forward slashes, or path itself if it is
+// not inside vendorDir.
+func relPath(vendorDir string, path string) string {
+	rel, err := filepath.Rel(vendorDir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// packageFiles lists the sources and resource files of the package, relative to vendorDir.
+// Packages vendored by the package, packages nested inside it and the directories in
+// ignoredDirs are left out.
+func packageFiles(vendorDir string, dep *common.PackageWrapper) (sources []string, resources []string, err error) {
+	root := dep.RootDir()
+	nestedVendor := filepath.Join(root, dep.VendorPath())
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path == root {
+				return nil
+			}
+			// Skip VCS metadata, tests, examples, vendored packages and packages nested
+			// inside this one
+			if strings.HasPrefix(info.Name(), ".") || ignoredDirs[strings.ToLower(info.Name())] || path == nestedVendor {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, core.PackageFile)); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if sourceExtensions[ext] {
+			sources = append(sources, relPath(vendorDir, path))
+		} else if ext == ".qrc" {
+			resources = append(resources, relPath(vendorDir, path))
+		}
+		return nil
+	})
+
+	return sources, resources, err
+}
+
+type cmakeInclude struct {
+	Dir  string
+	File string
+}
+
+type cmakePackages struct {
+	Sources   []string
+	Resources []string
+	Includes  []cmakeInclude
+}
+
+// add adds the files of the package. A package that declares its own CMake file is
+// included instead.
+func (c *cmakePackages) add(vendorDir string, dep *common.PackageWrapper) error {
+	if dep.CmakeFilename != "" {
+		c.Includes = append(c.Includes, cmakeInclude{
+			Dir:  relPath(vendorDir, dep.RootDir()),
+			File: relPath(vendorDir, filepath.Join(dep.RootDir(), dep.CmakeFilename)),
+		})
+		return nil
+	}
+
+	sources, resources, err := packageFiles(vendorDir, dep)
+	if err != nil {
+		return err
+	}
+	c.Sources = append(c.Sources, sources...)
+	c.Resources = append(c.Resources, resources...)
+	return nil
+}
+
+func init() {
+	core.RegisterGenerator("cmake", cmakeGenerator{})
+}
+
+// cmakeGenerator writes a vendor.cmake inside the vendor directory which adds the sources,
+// resources and QML import paths of the installed packages to a CMake target.
+type cmakeGenerator struct{}
+
+func (cmakeGenerator) Generate(vendorDir string, root *msg.Package, dependencies []*msg.Package) error {
+	data := &cmakePackages{}
+	for _, dep := range dependencies {
+		if err := data.add(vendorDir, wrapPackage(vendorDir, dep)); err != nil {
+			return err
+		}
+	}
+
+	vendorCMakeFile := filepath.Join(vendorDir, core.Vendor+".cmake")
+
+	return core.WriteTemplate(vendorCMakeFile, vendorCMake, data)
+}
@@ -0,0 +1,58 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+)
+
+func TestPackageFilesSkipsTestsAndExamples(t *testing.T) {
+	vendorDir, err := ioutil.TempDir("", "qpm-vendor-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vendorDir)
+
+	root := filepath.Join(vendorDir, "io", "qpm", "alpha")
+	for _, file := range []string{
+		"alpha.cpp",
+		"alpha.qrc",
+		"src/impl.h",
+		"tests/tst_alpha.cpp",
+		"Examples/demo/main.cpp",
+		"benchmarks/bench.cpp",
+		"vendor/io/qpm/beta/beta.cpp",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dep := &common.PackageWrapper{
+		Package:  &msg.Package{Name: "io.qpm.alpha"},
+		FilePath: filepath.Join(root, "qpm.json"),
+	}
+	sources, resources, err := packageFiles(vendorDir, dep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"io/qpm/alpha/alpha.cpp", "io/qpm/alpha/src/impl.h"}; !reflect.DeepEqual(sources, expected) {
+		t.Errorf("expected sources %v, got %v", expected, sources)
+	}
+	if expected := []string{"io/qpm/alpha/alpha.qrc"}; !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected resources %v, got %v", expected, resources)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"qpm.io/qpm/core"
+)
+
+type ConfigCommand struct {
+	BaseCommand
+	fs      *flag.FlagSet
+	project bool
+	system  bool
+}
+
+func NewConfigCommand(ctx core.Context) *ConfigCommand {
+	return &ConfigCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (c ConfigCommand) Description() string {
+	return "Shows and changes the configuration"
+}
+
+func (c *ConfigCommand) RegisterFlags(flags *flag.FlagSet) {
+	c.fs = flags
+
+	flags.BoolVar(&c.project, "project", false, "Change the project configuration in "+core.ProjectConfigPath())
+	flags.BoolVar(&c.system, "system", false, "Change the system configuration in "+core.SystemConfigPath())
+}
+
+func (c *ConfigCommand) Run() error {
+
+	switch c.fs.Arg(0) {
+	case "get":
+		return c.get(c.fs.Arg(1))
+	case "set":
+		return c.set(c.fs.Arg(1), c.fs.Arg(2))
+	case "list":
+		return c.list()
+	}
+
+	err := fmt.Errorf("Unknown config command %q, expected get, set or list", c.fs.Arg(0))
+	c.Error(err)
+	return err
+}
+
+// path returns the file that set changes.
+func (c *ConfigCommand) path() string {
+	if c.project {
+		return core.ProjectConfigPath()
+	} else if c.system {
+		return core.SystemConfigPath()
+	}
+	return core.UserConfigPath()
+}
+
+func (c *ConfigCommand) get(name string) error {
+	value, err := c.Ctx.Config.Get(name)
+	if err != nil {
+		c.Error(err)
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func (c *ConfigCommand) set(name string, value string) error {
+	path := c.path()
+
+	// Refuse what LoadConfig would ignore
+	if c.project {
+		if err := (&core.Config{}).CheckProjectSetting(name, value); err != nil {
+			c.Error(err)
+			return err
+		}
+	}
+
+	config, err := core.ReadConfig(path)
+	if err != nil {
+		c.Error(err)
+		return err
+	}
+
+	if err := config.Set(name, value); err != nil {
+		c.Error(err)
+		return err
+	}
+
+	if err := config.Save(path); err != nil {
+		c.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (c *ConfigCommand) list() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "Key\tValue\tDescription\t")
+	for _, key := range core.ConfigKeys {
+		value, _ := c.Ctx.Config.Get(key.Name)
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", key.Name, value, key.Description)
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+// generateBuildFiles runs the generators for the build systems listed in the package file,
+// or core.DefaultGenerators if none are listed, on the packages installed in vendorDir.
+func generateBuildFiles(vendorDir string, pkg *common.PackageWrapper) error {
+	root := pkg.Package
+	if root == nil {
+		root = &msg.Package{}
+	}
+
+	names := core.DefaultGenerators
+	if len(root.BuildSystems) > 0 {
+		names = root.BuildSystems
+	}
+
+	var generators []core.Generator
+	for _, name := range names {
+		generator, err := core.LookupGenerator(name)
+		if err != nil {
+			return fmt.Errorf("%s: %v", core.PackageFile, err)
+		}
+		generators = append(generators, generator)
+	}
+
+	depMap, err := common.LoadPackages(vendorDir)
+	if err != nil {
+		return err
+	}
+
+	// Packages come before the packages they depend on
+	var dependencies []*msg.Package
+	for _, dep := range common.NewDependencyGraph(pkg, depMap).Sorted() {
+		dependencies = append(dependencies, dep.Package)
+	}
+
+	for _, generator := range generators {
+		if err := generator.Generate(vendorDir, root, dependencies); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrapPackage returns a wrapper for a package installed in vendorDir.
+func wrapPackage(vendorDir string, pkg *msg.Package) *common.PackageWrapper {
+	return &common.PackageWrapper{
+		Package:  pkg,
+		FilePath: filepath.Join(core.PackageDir(vendorDir, pkg.Name), core.PackageFile),
+	}
+}
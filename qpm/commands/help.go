@@ -36,7 +36,7 @@ func (h *HelpCommand) Run() error {
 
 	switch commandName {
 	case "ping":
-		fmt.Println(`
+		fmt.Print(`
 Checks if we are able to reach the server.
 
 Usage:
@@ -44,7 +44,7 @@ Usage:
 `)
 
 	case "init":
-		fmt.Println(`
+		fmt.Print(`
 Generates the necessary files for publishing a package to the qpm registry.
 
 Usage:
@@ -52,50 +52,262 @@ Usage:
 `)
 
 	case "install":
-		fmt.Println(`
+		fmt.Print(`
 Installs the packages listed as dependencies in the package file or the given [PACKAGE].
+A semantic version range such as ^1.2, ~1.2.3 or ">=1.0 <2.0" can be given after the @.
+One version of every package is chosen so that all of the ranges in the dependency
+graph are satisfied. If that is not possible, the packages that conflict are listed.
+The registry only describes the dependencies of the latest release of each package, so
+older releases are assumed to have the same ones. A conflict that relies on such an
+assumption is shown as a warning and the versions chosen by the registry are installed.
+
+The exact revisions that were installed are recorded in qpm.lock. As long as the
+dependencies in the package file do not change, later installs use the lock file
+instead of resolving the dependencies again. Packages whose content does not match
+the hash they were published with are not installed.
+
+The signature of every package is checked as with qpm verify. The signaturePolicy
+setting decides what happens to packages that are unsigned, badly signed or signed
+with a key that is not trusted: off skips the check, warn (the default) installs them
+with a warning and require refuses to install them. Keys that are not in the keyring
+are fetched from the registry and the package is pinned to the key (see qpm help keys).
+
+With --offline, the packages are installed from qpm.lock and the local package cache
+without contacting the server or the package repositories. Any packages that are not
+in the cache are listed.
+
+The vendor directory also gets a vendor.pri for qmake projects and a vendor.cmake for
+CMake projects, which include the installed packages in the build. Set buildSystems in
+the package file to choose between qmake, cmake, qbs (qpm.qbs) and json (vendor.json).
+
+Several packages are installed at the same time. If some of them fail, the rest are
+still installed and all of the failures are listed at the end.
 
 Usage:
-	qpm install [PACKAGE]
+	qpm install [--frozen-lockfile] [--offline] [--jobs N] [--vendor-dir DIR] [PACKAGE[@RANGE]]
+
+Options:
+	--frozen-lockfile	Fail if qpm.lock is missing or out of date
+	--offline		Only install from qpm.lock and the local package cache
+	--jobs N		Install up to N packages at the same time (default 4)
+	--vendor-dir DIR	Install the packages in DIR instead of the vendorDir in the
+				package file or the configuration (default vendor)
+`)
+
+	case "update":
+		fmt.Print(`
+Updates the given [PACKAGE]s, or every dependency in the package file, to the newest
+version allowed by their version ranges. Exact versions are updated to the newest
+compatible version (same major version) and rewritten in the package file. The
+vendor.pri file and qpm.lock are updated to match, and packages that are no longer
+needed are removed from the vendor directory.
+
+Usage:
+	qpm update [--dry-run] [--jobs N] [--vendor-dir DIR] [PACKAGE...]
+
+Options:
+	--dry-run		Print the planned changes without applying them
+	--jobs N		Install up to N packages at the same time (default 4)
+	--vendor-dir DIR	The directory that packages are installed in
 `)
 
 	case "uninstall":
-		fmt.Println(`
+		fmt.Print(`
 Removes the given [PACKAGE] from the project and deletes the associated files.
 
 Usage:
-	qpm uninstall [PACKAGE]
+	qpm uninstall [--vendor-dir DIR] [PACKAGE]
+
+Options:
+	--vendor-dir DIR	The directory that packages are installed in
 `)
 
 	case "publish":
-		fmt.Println(`
-Publishes project as a package in the qpm registry.
+		fmt.Print(`
+Publishes project as a package in the qpm registry. The token used to publish is read
+from --token-file, the QPM_TOKEN environment variable, the registry's token setting or
+the token stored by qpm login, in that order. If there is none, you are asked to log in.
+
+A SHA-256 hash of the files in the commit being published, including those of its
+submodules, is published with the package and checked when it is installed. Uncommitted
+changes are left out.
 
 Usage:
-	qpm publish
+	qpm publish [--token-file FILE]
+
+Options:
+	--token-file FILE	Read the token from FILE
+`)
+
+	case "login":
+		fmt.Print(`
+Logs in to the default registry, or the one with the given address, and stores the
+token in the credentials file so that publish does not ask for a password. The file is
+only readable by the current user.
+
+Usage:
+	qpm login [--registry ADDRESS]
+`)
+
+	case "logout":
+		fmt.Print(`
+Removes the token stored by qpm login for the default registry, or the one with the
+given address.
+
+Usage:
+	qpm logout [--registry ADDRESS]
 `)
 
 	case "sign":
-		fmt.Println(`
+		fmt.Print(`
 Creates a PGP signature for contents of the project.
 
+The private key is read from secring.gpg in GNUPGHOME. Current versions of GnuPG no
+longer use that file, so export the key with gpg --export-secret-keys --armor and
+pass the file with --key instead.
+
 Usage:
-	qpm sign
+	qpm sign [--key FILE]
+
+Options:
+	--key FILE		Read the private key from the armored key FILE
+`)
+
+	case "keys":
+		fmt.Print(`
+Manages the keyring used to verify package signatures. A key must be imported and then
+trusted for the packages it signs. The prefix com.example trusts the key for
+com.example and every package whose name starts with com.example., and * trusts it
+for every package.
+
+Keys that are not in the keyring are fetched from the registry when a package signed
+with them is installed or verified. The package is then pinned to the key, and later
+versions signed with another key are rejected until the pin is revoked. Removed keys
+are revoked: packages signed with them are rejected and they are not fetched again
+unless they are imported.
+
+Usage:
+	qpm keys import [FILE...]			Imports armored public keys from FILE or standard input
+	qpm keys list					Lists the keys and the packages they are trusted for
+	qpm keys trust FINGERPRINT PREFIX...		Trusts the key for the packages with PREFIX
+	qpm keys revoke FINGERPRINT [PREFIX...]		Stops trusting the key for PREFIX or a pinned package,
+							or removes the key
+	qpm keys publish FILE				Uploads the armored public key in FILE to the registry
+
+Options:
+	--registry ADDRESS	The registry to publish the key to
+	--token-file FILE	Read the token used to publish from FILE instead of logging in
 `)
 
 	case "verify":
-		fmt.Println(`
+		fmt.Print(`
 Verifies the the content and publisher of the given [PACKAGE], provided the package has been signed.
+The signature must be made with the key whose fingerprint is in the package's qpm.json,
+and the key must be trusted for the package in the qpm keyring (see qpm help keys).
+Keys that are not in the keyring are fetched from the registry and pinned to the package.
+
+Usage:
+	qpm verify [--vendor-dir DIR] [PACKAGE]
+
+Options:
+	--vendor-dir DIR	The directory that packages are installed in
+`)
+
+	case "tree":
+		fmt.Print(`
+Prints the tree of packages installed in the vendor directory, starting from the
+dependencies in the package file. Packages that appear more than once are only
+expanded the first time and are marked with (*).
+
+Usage:
+	qpm tree [--json | --dot] [--vendor-dir DIR]
+
+Options:
+	--json			Print the tree as JSON
+	--dot			Print the graph in Graphviz dot format
+	--vendor-dir DIR	The directory that packages are installed in
+`)
+
+	case "why":
+		fmt.Print(`
+Shows every chain of dependencies that leads from the package file to the given
+[PACKAGE], along with the version range requested at each step.
+
+Usage:
+	qpm why [--vendor-dir DIR] PACKAGE
+
+Options:
+	--vendor-dir DIR	The directory that packages are installed in
+`)
+
+	case "outdated":
+		fmt.Print(`
+Checks the registry for newer versions of the installed packages. For each package it
+shows the installed version, the newest version allowed by the version ranges in the
+package files (if any) and the newest published version. The command exits with a
+non-zero status if any package is outdated.
+
+Usage:
+	qpm outdated [--json] [--vendor-dir DIR]
+
+Options:
+	--json			Print the report as JSON
+	--vendor-dir DIR	The directory that packages are installed in
+`)
+
+	case "cache":
+		fmt.Print(`
+Manages the cache of downloaded packages which is shared by all projects on this
+machine. The cache is stored in $XDG_CACHE_HOME/qpm unless the cacheDir setting is
+changed with qpm config. Installed files are copied from the cache, so editing them in
+the vendor directory does not change the cached copy. Packages are checked against the
+hash they were published with when they are cached; use verify to check that the cached
+files have not been modified since.
 
 Usage:
-	qpm verify [PACKAGE]
+	qpm cache list		Lists the cached packages
+	qpm cache clean		Removes every package from the cache
+	qpm cache verify	Checks that the cached files have not been modified
+`)
+
+	case "config":
+		fmt.Print(`
+Shows and changes the settings in the configuration files. The settings are read from
+the system file (/etc/qpmrc), the user file (~/.qpmrc) and the project file (.qpmrc in
+the current directory), in that order, with later files overriding earlier ones. The
+SERVER environment variable overrides the registry setting.
+
+The project file cannot set registry, caFile, proxy or cacheDir, can only set vendorDir
+to a directory inside the project, and can make signaturePolicy stricter but not weaker.
+Such settings are ignored with a warning.
+
+Usage:
+	qpm config get KEY					Prints the value of the setting
+	qpm config [--project | --system] set KEY VALUE	Changes the setting, an empty VALUE unsets it
+	qpm config list						Lists every setting and its value
+
+Options:
+	--project	Change the project file instead of the user file
+	--system	Change the system file instead of the user file
+
+Keys:
+	registry	Address of the default registry
+	caFile		PEM file used to verify the default registry
+	vendorDir	Directory that packages are installed in
+	license		License suggested by qpm init
+	author.name	Author name suggested by qpm init
+	author.email	Author email suggested by qpm init
+	signingKey	Fingerprint of the PGP key used by qpm sign
+	signaturePolicy	What install does with unsigned packages: off, warn or require
+	proxy		Proxy used for HTTP and HTTPS connections
+	cacheDir	Directory of the local package cache
 `)
 
 	case "help":
 		fallthrough
 
 	default:
-		fmt.Println(`
+		fmt.Print(`
 Shows the help text for the given [COMMAND]. If [COMMAND] is empty, it shows this text.
 
 Usage:
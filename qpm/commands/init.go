@@ -107,10 +107,16 @@ func (ic *InitCommand) Run() error {
 		return err
 	}
 
-	ic.Pkg.Author.Name, _ = publisher.LastCommitAuthorName()
+	ic.Pkg.Author.Name = ic.Ctx.Config.Author.Name
+	if ic.Pkg.Author.Name == "" {
+		ic.Pkg.Author.Name, _ = publisher.LastCommitAuthorName()
+	}
 	ic.Pkg.Author.Name, _ = <-Prompt("Your name:", ic.Pkg.Author.Name)
 
-	ic.Pkg.Author.Email, _ = publisher.LastCommitEmail()
+	ic.Pkg.Author.Email = ic.Ctx.Config.Author.Email
+	if ic.Pkg.Author.Email == "" {
+		ic.Pkg.Author.Email, _ = publisher.LastCommitEmail()
+	}
 	ic.Pkg.Author.Email = <-Prompt("Your email:", ic.Pkg.Author.Email)
 
 	cwd, err := os.Getwd()
@@ -138,7 +144,11 @@ func (ic *InitCommand) Run() error {
 		filename = ic.Pkg.PriFile()
 	}
 
-	license := <-Prompt("License:", "MIT")
+	license := ic.Ctx.Config.License
+	if license == "" {
+		license = "MIT"
+	}
+	license = <-Prompt("License:", license)
 
 	// convert Github style license strings
 	license = strings.ToUpper(regexGitHubLicense.ReplaceAllString(license, "_"))
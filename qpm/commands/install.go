@@ -9,8 +9,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-	"text/template"
+	"sync"
 
 	"golang.org/x/net/context"
 	"qpm.io/common"
@@ -19,33 +20,6 @@ import (
 	"qpm.io/qpm/vcs"
 )
 
-var packageFuncs = template.FuncMap{
-	"relPriFile": func(vendorDir string, dep *common.PackageWrapper) string {
-		abs := filepath.Join(dep.RootDir(), dep.PriFile())
-		rel, err := filepath.Rel(vendorDir, abs)
-		if err == nil {
-			return rel
-		} else {
-			return abs
-		}
-	},
-}
-
-var (
-	// This template is very dense to avoid excessive whitespace in the generated code.
-	// We can address this in a future version of Go (1.6?):
-	// https://github.com/golang/go/commit/e6ee26a03b79d0e8b658463bdb29349ca68e1460
-	vendorPri = template.Must(template.New("vendorPri").Funcs(packageFuncs).Parse(`
-DEFINES += QPM_INIT\\(E\\)=\"E.addImportPath(QStringLiteral(\\\"qrc:/\\\"));\"
-DEFINES += QPM_USE_NS
-INCLUDEPATH += $$PWD
-QML_IMPORT_PATH += $$PWD
-{{$vendirDir := .VendorDir}}
-{{range $dep := .Dependencies}}
-include($$PWD/{{relPriFile $vendirDir $dep}}){{end}}
-`))
-)
-
 type ProgressProxyReader struct {
 	io.Reader
 	total    int64
@@ -68,11 +42,23 @@ func (r *ProgressProxyReader) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// defaultJobs is the number of packages that are installed at the same time unless --jobs
+// is given.
+const defaultJobs = 4
+
 type InstallCommand struct {
 	BaseCommand
 	pkg       *common.PackageWrapper
+	lock      *common.LockFile
+	locked    []*common.LockedDependency
+	requested []string
 	fs        *flag.FlagSet
 	vendorDir string
+	frozen    bool
+	offline   bool
+	jobs      int
+	policy    string
+	verifier  *signatureVerifier
 }
 
 func NewInstallCommand(ctx core.Context) *InstallCommand {
@@ -90,17 +76,18 @@ func (i InstallCommand) Description() string {
 func (i *InstallCommand) RegisterFlags(flags *flag.FlagSet) {
 	i.fs = flags
 
-	// TODO: Support other directory names on the command line?
-	var err error
-	i.vendorDir, err = filepath.Abs(core.Vendor)
-	if err != nil {
-		i.vendorDir = core.Vendor
-	}
+	flags.BoolVar(&i.frozen, "frozen-lockfile", false, "Fail if "+core.LockFile+" is missing or does not match "+core.PackageFile)
+	flags.IntVar(&i.jobs, "jobs", defaultJobs, "The number of packages to install at the same time")
+	flags.BoolVar(&i.offline, "offline", false, "Install from "+core.LockFile+" and the local package cache without using the network")
+	flags.StringVar(&i.vendorDir, "vendor-dir", "", "The directory that packages are installed in")
 }
 
 func (i *InstallCommand) Run() error {
 
 	packageName := i.fs.Arg(0)
+	if packageName != "" {
+		i.requested = []string{packageName}
+	}
 
 	var err error
 	i.pkg, err = common.LoadPackage("")
@@ -126,6 +113,95 @@ func (i *InstallCommand) Run() error {
 		}
 	}
 
+	i.vendorDir = i.resolveVendorDir(i.vendorDir, i.pkg)
+
+	if err = i.loadTrustedKeys(); err != nil {
+		return err
+	}
+
+	i.lock, err = common.LoadLockFile("")
+	if err != nil && !os.IsNotExist(err) {
+		i.Error(err)
+		return err
+	}
+	lockMatches := err == nil && i.lock.Matches(i.pkg)
+
+	if i.frozen || i.offline {
+		if packageName != "" {
+			err = fmt.Errorf("Cannot add %s when using --frozen-lockfile or --offline", packageName)
+		} else if !lockMatches {
+			err = fmt.Errorf("%s is missing or out of date with %s", core.LockFile, core.PackageFile)
+		}
+		if err != nil {
+			i.Error(err)
+			return err
+		}
+	}
+
+	var dependencies []*msg.Dependency
+	if packageName == "" && lockMatches {
+		// Nothing has changed since the last resolution so skip asking the server
+		for _, d := range i.lock.Dependencies {
+			dependencies = append(dependencies, d.Dependency())
+		}
+	} else {
+		dependencies, err = i.resolve(packageName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(dependencies) == 0 {
+		i.Info("No package(s) found")
+		return nil
+	}
+
+	if i.offline {
+		if err = i.checkCache(dependencies); err != nil {
+			i.Error(err)
+			return err
+		}
+	}
+
+	// create the vendor directory if needed
+	if err = os.MkdirAll(i.vendorDir, 0755); err != nil {
+		i.Error(err)
+		return err
+	}
+
+	// Download and extract the packages
+	packages, err := i.installAll(dependencies)
+	if err != nil {
+		return err
+	}
+
+	// Save the dependencies in the package file
+	err = i.save(packages)
+	// FIXME: should we continue installing ?
+	if err != nil {
+		return err
+	}
+
+	// Record exactly what was installed. Adding a single package only gives a complete
+	// lock if the rest of it was already up to date.
+	err = i.saveLock(packageName == "", packageName == "" || lockMatches)
+	if err != nil {
+		return err
+	}
+
+	err = i.postInstall()
+	// FIXME: should we continue installing ?
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resolve asks the server for the full list of dependencies needed to install the given
+// package, or all of the packages in the package file if packageName is empty.
+func (i *InstallCommand) resolve(packageName string) ([]*msg.Dependency, error) {
+
 	var packageNames []string
 	if packageName == "" {
 		packageNames = i.pkg.Dependencies
@@ -140,12 +216,7 @@ func (i *InstallCommand) Run() error {
 	})
 	if err != nil {
 		i.Error(err)
-		return err
-	}
-
-	if len(response.Dependencies) == 0 {
-		i.Info("No package(s) found")
-		return nil
+		return nil, err
 	}
 
 	// Show info, warnings, errors and address prompts before continuing
@@ -161,83 +232,295 @@ func (i *InstallCommand) Run() error {
 			if len(continueAnyway) == 0 || strings.ToLower(string(continueAnyway[0])) == "y" {
 				continue
 			} else {
-				return fmt.Errorf("Installation aborted.")
+				return nil, fmt.Errorf("Installation aborted.")
 			}
 		}
 	}
 
-	// create the vendor directory if needed
-	if _, err = os.Stat(i.vendorDir); err != nil {
-		err = os.Mkdir(i.vendorDir, 0755)
+	// The server's answer is only a hint, the versions are chosen locally so that conflicts
+	// between packages can be reported.
+	resolver := common.NewResolver(newRegistrySource(i.Ctx.Client))
+	for _, d := range response.Dependencies {
+		resolver.Hint(d.Name, d.Version.Label)
 	}
 
-	// Download and extract the packages
-	packages := []*common.PackageWrapper{}
-	for _, d := range response.Dependencies {
-		p, err := i.install(d)
-		if err != nil {
-			return err
+	rootDeps := i.pkg.Dependencies
+	if packageName != "" {
+		rootDeps = []string{packageName}
+		newName, _ := common.ParseDependency(packageName)
+		for _, d := range i.pkg.Dependencies {
+			if name, _ := common.ParseDependency(d); name != newName {
+				rootDeps = append(rootDeps, d)
+			}
 		}
-		packages = append(packages, p)
 	}
 
-	// Save the dependencies in the package file
-	err = i.save(packages)
-	// FIXME: should we continue installing ?
+	resolution, err := resolver.Resolve(i.rootName(), rootDeps)
 	if err != nil {
-		return err
+		// The dependencies of older releases are only estimated, so only a conflict that
+		// does not rely on them overrides the server
+		if conflict, ok := err.(*common.ConflictError); ok && !conflict.Assumed() {
+			i.Error(err)
+			return nil, err
+		}
+		i.Warning("Could not resolve the dependencies locally, using the server's versions: " + err.Error())
+		return response.Dependencies, nil
 	}
 
-	err = i.postInstall()
-	// FIXME: should we continue installing ?
-	if err != nil {
-		return err
+	if packageName == "" {
+		return resolution.Dependencies, nil
 	}
+	newName, _ := common.ParseDependency(packageName)
+	return resolution.Closure([]string{newName}), nil
+}
 
+// checkCache makes sure that every dependency can be installed from the local package cache
+// and lists all of the ones that cannot.
+func (i *InstallCommand) checkCache(dependencies []*msg.Dependency) error {
+	cache := vcs.NewCache(i.Ctx.CacheDir())
+
+	var missing []string
+	for _, d := range dependencies {
+		if cache.Lookup(d.Repository, d.Version) == nil {
+			missing = append(missing, fmt.Sprintf("\t%s@%s (%s)", d.Name, d.Version.Label, d.Version.Revision))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("The following packages are not in the local package cache (%s):\n%s",
+			cache.Dir, strings.Join(missing, "\n"))
+	}
 	return nil
 }
 
-func (i *InstallCommand) install(d *msg.Dependency) (*common.PackageWrapper, error) {
+func (i *InstallCommand) rootName() string {
+	if i.pkg.Name != "" {
+		return i.pkg.Name
+	}
+	return core.PackageFile
+}
 
-	signature := strings.Join([]string{d.Name, d.Version.Label}, "@")
-	fmt.Println("Installing", signature)
+// installAll installs the dependencies using up to i.jobs workers. A failure does not stop
+// the other packages from being installed, instead all of the failures are reported once
+// everything has finished. The packages are returned in the same order as the dependencies.
+func (i *InstallCommand) installAll(dependencies []*msg.Dependency) ([]*common.PackageWrapper, error) {
 
-	installer, err := vcs.CreateInstaller(d.Repository)
-	if err != nil {
+	jobs := i.jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	packages := make([]*common.PackageWrapper, len(dependencies))
+	locked := make([]*common.LockedDependency, len(dependencies))
+	errs := make([]error, len(dependencies))
+
+	// Installing a package replaces its directory, which also holds the packages nested
+	// inside it, eg: com.foo.bar inside com.foo. Outer packages are started first and the
+	// packages inside them wait for them to finish.
+	finished := make([]chan struct{}, len(dependencies))
+	for n := range finished {
+		finished[n] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	var output sync.Mutex
+	done := 0
+	indexes := make(chan int)
+
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range indexes {
+				d := dependencies[n]
+				for m, outer := range dependencies {
+					if nestedIn(outer.Name, d.Name) {
+						<-finished[m]
+					}
+				}
+				packages[n], locked[n], errs[n] = i.install(d)
+				close(finished[n])
+
+				output.Lock()
+				done++
+				status := "Installed"
+				if errs[n] != nil {
+					status = "Failed"
+				}
+				fmt.Printf("[%d/%d] %s %s@%s\n", done, len(dependencies), status, d.Name, d.Version.Label)
+				output.Unlock()
+			}
+		}()
+	}
+
+	order := make([]int, len(dependencies))
+	for n := range order {
+		order[n] = n
+	}
+	sort.Stable(byNesting{order, dependencies})
+
+	for _, n := range order {
+		indexes <- n
+	}
+	close(indexes)
+	wg.Wait()
+
+	var failures []string
+	for n, err := range errs {
+		if err != nil {
+			d := dependencies[n]
+			failures = append(failures, fmt.Sprintf("\t%s@%s: %v", d.Name, d.Version.Label, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		err := fmt.Errorf("The following packages could not be installed:\n%s", strings.Join(failures, "\n"))
 		i.Error(err)
 		return nil, err
 	}
 
-	destination := i.vendorDir + string(filepath.Separator) + strings.Replace(d.Name, ".", string(filepath.Separator), -1)
+	i.locked = append(i.locked, locked...)
+
+	return packages, nil
+}
+
+// nestedIn reports whether the package called inner is installed inside the directory of
+// the package called outer.
+func nestedIn(outer string, inner string) bool {
+	return strings.HasPrefix(inner, outer+".")
+}
+
+// byNesting sorts dependency indexes so that packages come before the packages nested
+// inside them.
+type byNesting struct {
+	indexes      []int
+	dependencies []*msg.Dependency
+}
+
+func (b byNesting) Len() int      { return len(b.indexes) }
+func (b byNesting) Swap(i, j int) { b.indexes[i], b.indexes[j] = b.indexes[j], b.indexes[i] }
+func (b byNesting) Less(i, j int) bool {
+	return strings.Count(b.dependencies[b.indexes[i]].Name, ".") < strings.Count(b.dependencies[b.indexes[j]].Name, ".")
+}
+
+// install installs a single dependency into the vendor directory and returns what should be
+// recorded for it in the lock file. It is safe to call from multiple goroutines.
+func (i *InstallCommand) install(d *msg.Dependency) (*common.PackageWrapper, *common.LockedDependency, error) {
+
+	var installer vcs.Installer
+	var err error
+	if i.offline {
+		installer = vcs.CreateOfflineInstaller(i.Ctx.CacheDir())
+	} else {
+		installer, err = vcs.CreateInstaller(d.Repository, i.Ctx.CacheDir())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	destination := core.PackageDir(i.vendorDir, d.Name)
 	pkg, err := installer.Install(d.Repository, d.Version, destination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash, err := common.HashTree(destination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The content must be what the author published
+	if d.Version.Hash != "" && d.Version.Hash != hash {
+		os.RemoveAll(destination)
+		return nil, nil, fmt.Errorf("The content does not match the hash it was published with (expected %s, got %s)", d.Version.Hash, hash)
+	}
+
+	if err = i.checkSignature(pkg, hash); err != nil {
+		os.RemoveAll(destination)
+		return nil, nil, err
+	}
+
+	// The same revision must always produce the same content
+	if l := i.lock.Find(d.Name); l != nil && l.Revision == d.Version.Revision && l.Hash != "" && l.Hash != hash {
+		os.RemoveAll(destination)
+		return nil, nil, fmt.Errorf("The content does not match the hash in %s", core.LockFile)
+	}
+
+	return pkg, common.NewLockedDependency(d, hash), nil
+}
+
+// loadTrustedKeys reads the signature policy and, unless it is off, the keyring with the
+// keys of trusted publishers. Unknown keys are fetched from the registry.
+func (i *InstallCommand) loadTrustedKeys() error {
+	var err error
+	i.policy, err = i.Ctx.SignaturePolicy()
 	if err != nil {
 		i.Error(err)
-		return nil, err
+		return err
+	}
+
+	if i.policy == core.SignaturesOff {
+		return nil
+	}
+
+	// Unknown keys cannot be fetched when offline
+	client := i.Ctx.Client
+	if i.offline {
+		client = nil
+	}
+
+	i.verifier, err = newSignatureVerifier(i.BaseCommand, client)
+	if err != nil {
+		i.Error(err)
+		return err
+	}
+	return nil
+}
+
+// checkSignature verifies the signature of an installed package according to the signature
+// policy. Unsigned and badly signed packages are only refused if signatures are required.
+func (i *InstallCommand) checkSignature(pkg *common.PackageWrapper, hash string) error {
+	if i.policy == core.SignaturesOff {
+		return nil
 	}
 
-	return pkg, nil
+	err := i.verifier.verify(pkg, hash)
+	if err != nil && i.policy != core.SignaturesRequire {
+		i.Warning(err.Error())
+		return nil
+	}
+	return err
 }
 
 func (i *InstallCommand) save(newDeps []*common.PackageWrapper) error {
 
 	existingDeps := i.pkg.ParseDependencies()
+	requestedDeps := common.NewDependencyList(i.requested)
 
 	for _, d := range newDeps {
-		existingVersion, exists := existingDeps[d.Name]
+		// Keep the range the user asked for if the installed version satisfies it
+		signature := d.GetDependencySignature()
+		if r := requestedDeps[d.Name]; r != "" && common.Satisfies(d.Version.Label, r) {
+			signature = strings.Join([]string{d.Name, r}, "@")
+		}
+
+		existingRange, exists := existingDeps[d.Name]
 		if exists {
-			if d.Version.Label != existingVersion {
-				existingSignature := strings.Join([]string{d.Name, existingVersion}, "@")
-				message := fmt.Sprint(existingSignature, " is already a dependency. Replacing with version ", d.Version.Label, ".")
-				i.Warning(message)
-				for n, e := range i.pkg.Dependencies {
-					if existingSignature == e {
-						i.pkg.Dependencies[n] = d.GetDependencySignature()
-						break
+			if _, requested := requestedDeps[d.Name]; !requested && common.Satisfies(d.Version.Label, existingRange) {
+				continue
+			}
+			for n, e := range i.pkg.Dependencies {
+				if name, _ := common.ParseDependency(e); name == d.Name {
+					if e != signature {
+						message := fmt.Sprint(e, " is already a dependency. Replacing with ", signature, ".")
+						i.Warning(message)
+						i.pkg.Dependencies[n] = signature
 					}
+					break
 				}
 			}
 		} else {
-			i.pkg.Dependencies = append(i.pkg.Dependencies, d.GetDependencySignature())
+			i.pkg.Dependencies = append(i.pkg.Dependencies, signature)
 		}
 	}
 
@@ -250,38 +533,33 @@ func (i *InstallCommand) save(newDeps []*common.PackageWrapper) error {
 	return nil
 }
 
-func (i *InstallCommand) postInstall() error {
-	if err := GenerateVendorPri(i.vendorDir, i.pkg); err != nil {
+// saveLock writes the dependencies installed by this command to the lock file. If replace is
+// true, the dependencies make up the full tree and any other entries are dropped. The lock is
+// only marked as matching the package file if it is complete.
+func (i *InstallCommand) saveLock(replace bool, complete bool) error {
+	if replace {
+		i.lock.Dependencies = []*common.LockedDependency{}
+	}
+	for _, l := range i.locked {
+		i.lock.Put(l)
+	}
+	if complete {
+		i.lock.PackageHash = common.DependencyHash(i.pkg.Dependencies)
+	} else {
+		i.lock.PackageHash = ""
+	}
+
+	if err := i.lock.Save(); err != nil {
 		i.Error(err)
 		return err
 	}
 	return nil
 }
 
-// Generates a vendor.pri inside vendorDir using the information contained in the package file
-// and the dependencies
-func GenerateVendorPri(vendorDir string, pkg *common.PackageWrapper) error {
-	depMap, err := common.LoadPackages(vendorDir)
-	if err != nil {
+func (i *InstallCommand) postInstall() error {
+	if err := generateBuildFiles(i.vendorDir, i.pkg); err != nil {
+		i.Error(err)
 		return err
 	}
-
-	var deps []*common.PackageWrapper
-	for _, dep := range depMap {
-		deps = append(deps, dep)
-	}
-
-	vendorPriFile := filepath.Join(vendorDir, core.Vendor+".pri")
-
-	data := struct {
-		VendorDir    string
-		Package      *common.PackageWrapper
-		Dependencies []*common.PackageWrapper
-	}{
-		vendorDir,
-		pkg,
-		deps,
-	}
-
-	return core.WriteTemplate(vendorPriFile, vendorPri, data)
+	return nil
 }
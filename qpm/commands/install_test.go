@@ -0,0 +1,261 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+	qpmtesting "qpm.io/qpm/testing"
+)
+
+type command interface {
+	RegisterFlags(flags *flag.FlagSet)
+	Run() error
+}
+
+// run parses args for the command and runs it.
+func run(t *testing.T, c command, args ...string) error {
+	flags := flag.NewFlagSet("qpm", flag.ContinueOnError)
+	c.RegisterFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return c.Run()
+}
+
+// newFixture returns a fixture with its own cache and configuration directories. The
+// returned function removes it again.
+func newFixture(t *testing.T) (*qpmtesting.Fixture, func()) {
+	f, err := qpmtesting.NewFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"XDG_CACHE_HOME":  f.CacheDir(),
+		"XDG_CONFIG_HOME": filepath.Join(f.Dir, "config"),
+		"QPM_TOKEN":       "",
+	}
+	saved := make(map[string]string)
+	for key, value := range env {
+		saved[key] = os.Getenv(key)
+		os.Setenv(key, value)
+	}
+
+	return f, func() {
+		for key, value := range saved {
+			os.Setenv(key, value)
+		}
+		f.Remove()
+	}
+}
+
+// chdir changes to dir until the returned function is called.
+func chdir(t *testing.T, dir string) func() {
+	restore, err := qpmtesting.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if err := restore(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	client := qpmtesting.NewFakeClient()
+	alpha, err := f.Release("io.qpm.alpha", "1.0.0", nil, "alpha.h", "int alpha;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, err := f.Release("io.qpm.beta", "1.2.0", []string{"io.qpm.alpha@^1.0"}, "beta.h", "int beta;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Add(alpha, beta); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := f.Project("app", "io.qpm.beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	if err = run(t, NewInstallCommand(client.Context()), "--jobs", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"vendor/io/qpm/alpha/alpha.h", "vendor/io/qpm/beta/beta.h", "vendor/vendor.pri"} {
+		if _, err := os.Stat(filepath.Join(project, filepath.FromSlash(file))); err != nil {
+			t.Errorf("%s was not installed: %v", file, err)
+		}
+	}
+
+	lock, err := common.LoadLockFile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range []string{"io.qpm.alpha", "io.qpm.beta"} {
+		if l := lock.Find(pkg); l == nil || l.Hash == "" {
+			t.Errorf("%s is not locked with a hash: %+v", pkg, l)
+		}
+	}
+
+	calls := client.Calls()
+	if len(calls) == 0 || calls[0] != "GetDependencies" {
+		t.Errorf("expected the dependencies to be fetched first, got %v", calls)
+	}
+}
+
+func TestInstallRejectsModifiedPackage(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	client := qpmtesting.NewFakeClient()
+	alpha, err := f.Release("io.qpm.alpha", "1.0.0", nil, "alpha.h", "int alpha;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha.Version.Hash = strings.Repeat("0", 64)
+	if err = client.Add(alpha); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := f.Project("app", "io.qpm.alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	if err = run(t, NewInstallCommand(client.Context())); err == nil {
+		t.Fatal("expected the install to fail")
+	}
+	dir := core.PackageDir(filepath.Join(project, core.Vendor), "io.qpm.alpha")
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s was left behind", dir)
+	}
+}
+
+func TestInstallFallsBackOnAssumedConflicts(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	client := qpmtesting.NewFakeClient()
+	var releases []*msg.Package
+	for _, r := range []struct {
+		name, label string
+		deps        []string
+	}{
+		{"io.qpm.gamma", "1.0.0", nil},
+		{"io.qpm.gamma", "2.0.0", nil},
+		// Only the newest release of alpha needs gamma 2, which the client cannot see
+		{"io.qpm.alpha", "1.0.0", []string{"io.qpm.gamma@^1.0"}},
+		{"io.qpm.alpha", "1.1.0", []string{"io.qpm.gamma@^2.0"}},
+	} {
+		pkg, err := f.Release(r.name, r.label, r.deps, r.name+".h", r.label+"\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, pkg)
+	}
+	if err := client.Add(releases...); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := f.Project("app", "io.qpm.alpha@~1.0.0", "io.qpm.gamma@^1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	if err = run(t, NewInstallCommand(client.Context())); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := common.LoadLockFile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, label := range map[string]string{"io.qpm.alpha": "1.0.0", "io.qpm.gamma": "1.0.0"} {
+		if l := lock.Find(name); l == nil || l.Version != label {
+			t.Errorf("expected %s@%s to be installed, got %+v", name, label, l)
+		}
+	}
+}
+
+// stdin replaces standard input with the given text until the returned function is called.
+func stdin(t *testing.T, text string) func() {
+	file, err := ioutil.TempFile("", "qpm-stdin-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(file.Name())
+	if _, err = file.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := os.Stdin
+	os.Stdin = file
+	return func() {
+		os.Stdin = saved
+		file.Close()
+	}
+}
+
+func TestInstallRejectsPackageChangedSinceLocked(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	client := qpmtesting.NewFakeClient()
+	alpha, err := f.Release("io.qpm.alpha", "1.0.0", nil, "alpha.h", "int alpha;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Add(alpha); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := f.Project("app", "io.qpm.alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	if err = run(t, NewInstallCommand(client.Context())); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := ioutil.ReadFile(core.LockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock = []byte(strings.Replace(string(lock), alpha.Version.Hash, strings.Repeat("0", 64), -1))
+	if err = ioutil.WriteFile(core.LockFile, lock, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = run(t, NewInstallCommand(client.Context())); err == nil {
+		t.Fatal("expected the install to fail")
+	}
+	dir := core.PackageDir(filepath.Join(project, core.Vendor), "io.qpm.alpha")
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s was left behind", dir)
+	}
+}
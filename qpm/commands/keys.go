@@ -0,0 +1,241 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/net/context"
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+type KeysCommand struct {
+	BaseCommand
+	fs        *flag.FlagSet
+	keyring   *common.Keyring
+	registry  string
+	tokenFile string
+}
+
+func NewKeysCommand(ctx core.Context) *KeysCommand {
+	return &KeysCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (k KeysCommand) Description() string {
+	return "Manages the keys of trusted package publishers"
+}
+
+func (k *KeysCommand) RegisterFlags(flags *flag.FlagSet) {
+	k.fs = flags
+
+	flags.StringVar(&k.registry, "registry", "", "The address of the registry to publish the key to")
+	flags.StringVar(&k.tokenFile, "token-file", "", "Read the token used to publish from the given file instead of logging in")
+}
+
+func (k *KeysCommand) Run() error {
+
+	var err error
+	k.keyring, err = common.LoadKeyring()
+	if err != nil {
+		k.Error(err)
+		return err
+	}
+
+	args := k.fs.Args()
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	switch args[0] {
+	case "import":
+		err = k.importKeys(args[1:])
+	case "list":
+		return k.list()
+	case "publish":
+		return k.publish(args[1:])
+	case "trust":
+		err = k.trust(args[1:])
+	case "revoke":
+		err = k.revoke(args[1:])
+	default:
+		err = fmt.Errorf("Unknown keys command %q, expected import, list, publish, trust or revoke", args[0])
+	}
+
+	if err == nil {
+		err = k.keyring.Save()
+	}
+	if err != nil {
+		k.Error(err)
+	}
+	return err
+}
+
+// importKeys adds the armored public keys in the given files, or standard input if there
+// are none, to the keyring.
+func (k *KeysCommand) importKeys(files []string) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, name := range files {
+		if err := k.importFile(name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Run 'qpm keys trust FINGERPRINT PREFIX' to trust a key for packages.")
+	return nil
+}
+
+// importFile adds the armored public keys in the named file, or standard input if the name
+// is "-", to the keyring.
+func (k *KeysCommand) importFile(name string) error {
+	var reader io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	keys, err := k.keyring.Import(reader)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	for _, key := range keys {
+		fmt.Printf("Imported %s %s\n", key.Fingerprint, key.Identity)
+	}
+	return nil
+}
+
+func (k *KeysCommand) list() error {
+	if len(k.keyring.Keys) == 0 {
+		fmt.Println("There are no keys in " + common.KeyringPath())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "Fingerprint\tIdentity\tTrusted for\t")
+	for _, key := range k.keyring.Keys {
+		trusted := append([]string{}, key.Prefixes...)
+		for _, name := range k.keyring.PinnedPackages(key) {
+			trusted = append(trusted, name+" (pinned)")
+		}
+		prefixes := strings.Join(trusted, ", ")
+		if prefixes == "" {
+			prefixes = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", key.Fingerprint, key.Identity, prefixes)
+	}
+	w.Flush()
+
+	return nil
+}
+
+// publish uploads the armored public key in the given file to the registry so that installs
+// can fetch it when they find packages signed with it.
+func (k *KeysCommand) publish(args []string) error {
+	if len(args) != 1 {
+		err := fmt.Errorf("Expected the FILE with the public key to publish")
+		k.Error(err)
+		return err
+	}
+
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		k.Error(err)
+		return err
+	}
+
+	client := k.Ctx.Client
+	var registry *core.Registry
+	if k.Ctx.Registries != nil {
+		if registry, err = registryFor(k.Ctx, k.registry); err != nil {
+			k.Error(err)
+			return err
+		}
+		client = registry.Client()
+	}
+
+	token, err := publishToken(client, registry, k.tokenFile)
+	if err != nil {
+		k.Error(err)
+		return err
+	}
+
+	response, err := client.PublishKey(context.Background(), &msg.PublishKeyRequest{
+		Token:     token,
+		PublicKey: string(data),
+	})
+	if err != nil {
+		k.Error(err)
+		return err
+	}
+
+	fmt.Println("Published " + response.Fingerprint)
+	return nil
+}
+
+func (k *KeysCommand) find(args []string) (*common.TrustedKey, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("No FINGERPRINT given")
+	}
+	key := k.keyring.Find(args[0])
+	if key == nil {
+		return nil, fmt.Errorf("The key %s is not in the keyring, import it first", args[0])
+	}
+	return key, nil
+}
+
+// trust allows a key to sign the packages with the given prefixes.
+func (k *KeysCommand) trust(args []string) error {
+	key, err := k.find(args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("No PREFIX given, use %s to trust the key for every package", common.AnyPackage)
+	}
+
+	for _, prefix := range args[1:] {
+		key.Trust(prefix)
+		fmt.Printf("Trusting %s for %s\n", key.Fingerprint, prefix)
+	}
+	return nil
+}
+
+// revoke stops trusting a key for the given prefixes, or removes it if there are none.
+func (k *KeysCommand) revoke(args []string) error {
+	key, err := k.find(args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		fmt.Printf("Removing %s %s\n", key.Fingerprint, key.Identity)
+		return k.keyring.Remove(key.Fingerprint)
+	}
+
+	for _, prefix := range args[1:] {
+		if err := k.keyring.Revoke(key, prefix); err != nil {
+			return err
+		}
+		fmt.Printf("No longer trusting %s for %s\n", key.Fingerprint, prefix)
+	}
+	return nil
+}
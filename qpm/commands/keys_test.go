@@ -0,0 +1,82 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+	"qpm.io/common"
+	"qpm.io/qpm/core"
+	qpmtesting "qpm.io/qpm/testing"
+)
+
+func TestInstallRefusesRevokedKeys(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	signer, err := openpgp.NewEntity("Test", "", qpmtesting.User, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := common.ArmoredPublicKey(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := common.KeyFingerprint(signer)
+
+	alpha, err := f.SignedRelease(signer, "io.qpm.alpha", "1.0.0", nil, "alpha.h", "int alpha;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := qpmtesting.NewFakeClient()
+	if err = client.Add(alpha); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.AddKey(publicKey); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := f.Project("app", "io.qpm.alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	ctx := client.Context()
+	ctx.Config.SignaturePolicy = core.SignaturesRequire
+
+	// The key is fetched from the registry and trusted on first use
+	if err = run(t, NewInstallCommand(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	if err = run(t, NewKeysCommand(ctx), "revoke", fingerprint); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = run(t, NewInstallCommand(ctx)); err == nil {
+		t.Fatal("expected the install to fail with the revoked key")
+	}
+	keyring, err := common.LoadKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyring.Find(fingerprint) != nil {
+		t.Errorf("expected the revoked key not to be fetched again")
+	}
+
+	// Importing the key explicitly trusts it again
+	file := filepath.Join(f.Dir, "key.asc")
+	if err = ioutil.WriteFile(file, []byte(publicKey), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = run(t, NewKeysCommand(ctx), "import", file); err != nil {
+		t.Fatal(err)
+	}
+	if err = run(t, NewInstallCommand(ctx)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/net/context"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+// registryFor returns the registry with the given address, or the default registry if the
+// address is empty.
+func registryFor(ctx core.Context, address string) (*core.Registry, error) {
+	if ctx.Registries == nil {
+		return nil, fmt.Errorf("No registries are configured")
+	}
+	if address == "" {
+		return ctx.Registries.Default(), nil
+	}
+	if registry := ctx.Registries.ByAddress(address); registry != nil {
+		return registry, nil
+	}
+	return nil, fmt.Errorf("Unknown registry %s, add it to %s first", address, core.RegistriesFile)
+}
+
+// publishToken returns the token used to publish to the registry, which may be nil if
+// there are no registries. The token is read from tokenFile, the QPM_TOKEN environment
+// variable, the registry's token setting or the token stored by qpm login, in that order.
+// If there is none, the user is asked to log in using the client.
+func publishToken(client msg.QpmClient, registry *core.Registry, tokenFile string) (string, error) {
+	var address string
+	var token string
+	if registry != nil {
+		address = registry.Address
+		token = registry.Token
+	}
+
+	if tokenFile != "" {
+		data, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return "", err
+		}
+		token = strings.TrimSpace(string(data))
+	} else if env := os.Getenv("QPM_TOKEN"); env != "" {
+		token = env
+	} else if token == "" && address != "" {
+		credentials, err := core.LoadCredentials()
+		if err != nil {
+			return "", err
+		}
+		token = credentials.Token(address)
+	}
+
+	if token == "" {
+		return LoginPrompt(context.Background(), client)
+	}
+	return token, nil
+}
+
+type LoginCommand struct {
+	BaseCommand
+	registry string
+}
+
+func NewLoginCommand(ctx core.Context) *LoginCommand {
+	return &LoginCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (l LoginCommand) Description() string {
+	return "Logs in to a registry and stores the token"
+}
+
+func (l *LoginCommand) RegisterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&l.registry, "registry", "", "The address of the registry to log in to")
+}
+
+func (l *LoginCommand) Run() error {
+
+	registry, err := registryFor(l.Ctx, l.registry)
+	if err != nil {
+		l.Error(err)
+		return err
+	}
+
+	credentials, err := core.LoadCredentials()
+	if err != nil {
+		l.Error(err)
+		return err
+	}
+
+	fmt.Println("Logging in to " + registry.Address)
+	token, err := LoginPrompt(context.Background(), registry.Client())
+	if err != nil {
+		l.Error(err)
+		return err
+	}
+
+	credentials.SetToken(registry.Address, token)
+	if err := credentials.Save(); err != nil {
+		l.Error(err)
+		return err
+	}
+
+	fmt.Println("Logged in, the token is stored in " + core.CredentialsPath())
+	return nil
+}
+
+type LogoutCommand struct {
+	BaseCommand
+	registry string
+}
+
+func NewLogoutCommand(ctx core.Context) *LogoutCommand {
+	return &LogoutCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (l LogoutCommand) Description() string {
+	return "Removes the stored token for a registry"
+}
+
+func (l *LogoutCommand) RegisterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&l.registry, "registry", "", "The address of the registry to log out of")
+}
+
+func (l *LogoutCommand) Run() error {
+
+	registry, err := registryFor(l.Ctx, l.registry)
+	if err != nil {
+		l.Error(err)
+		return err
+	}
+
+	credentials, err := core.LoadCredentials()
+	if err != nil {
+		l.Error(err)
+		return err
+	}
+
+	if credentials.Token(registry.Address) == "" {
+		fmt.Println("Not logged in to " + registry.Address)
+		return nil
+	}
+
+	credentials.SetToken(registry.Address, "")
+	if err := credentials.Save(); err != nil {
+		l.Error(err)
+		return err
+	}
+
+	fmt.Println("Logged out of " + registry.Address)
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+func init() {
+	core.RegisterGenerator("json", manifestGenerator{})
+}
+
+type manifestPackage struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version,omitempty"`
+	Revision     string   `json:"revision,omitempty"`
+	Path         string   `json:"path"`
+	PriFile      string   `json:"priFile,omitempty"`
+	CMakeFile    string   `json:"cmakeFile,omitempty"`
+	Sources      []string `json:"sources"`
+	Resources    []string `json:"resources"`
+	Dependencies []string `json:"dependencies"`
+}
+
+type manifest struct {
+	Package  string             `json:"package,omitempty"`
+	Packages []*manifestPackage `json:"packages"`
+}
+
+// manifestGenerator writes a vendor.json inside the vendor directory which describes the
+// installed packages for tools that do not understand the other build files. Paths are
+// relative to the vendor directory.
+type manifestGenerator struct{}
+
+func (manifestGenerator) Generate(vendorDir string, root *msg.Package, dependencies []*msg.Package) error {
+	m := &manifest{
+		Package:  root.Name,
+		Packages: []*manifestPackage{},
+	}
+
+	for _, d := range dependencies {
+		dep := wrapPackage(vendorDir, d)
+		sources, resources, err := packageFiles(vendorDir, dep)
+		if err != nil {
+			return err
+		}
+
+		p := &manifestPackage{
+			Name:         dep.Name,
+			Path:         relPath(vendorDir, dep.RootDir()),
+			PriFile:      relPath(vendorDir, filepath.Join(dep.RootDir(), dep.PriFile())),
+			Sources:      sources,
+			Resources:    resources,
+			Dependencies: dep.Dependencies,
+		}
+		if dep.Version != nil {
+			p.Version = dep.Version.Label
+			p.Revision = dep.Version.Revision
+		}
+		if dep.CmakeFilename != "" {
+			p.CMakeFile = relPath(vendorDir, filepath.Join(dep.RootDir(), dep.CmakeFilename))
+		}
+		if p.Sources == nil {
+			p.Sources = []string{}
+		}
+		if p.Resources == nil {
+			p.Resources = []string{}
+		}
+		if p.Dependencies == nil {
+			p.Dependencies = []string{}
+		}
+		m.Packages = append(m.Packages, p)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	vendorJsonFile := filepath.Join(vendorDir, core.Vendor+".json")
+
+	return ioutil.WriteFile(vendorJsonFile, append(data, '\n'), 0644)
+}
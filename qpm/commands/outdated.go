@@ -0,0 +1,196 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"golang.org/x/net/context"
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/common/semver"
+	"qpm.io/qpm/core"
+)
+
+// The number of Info requests that are sent to the server at the same time.
+const outdatedConcurrency = 8
+
+type outdatedPackage struct {
+	Name     string `json:"name"`
+	Current  string `json:"current"`
+	Wanted   string `json:"wanted,omitempty"`
+	Latest   string `json:"latest,omitempty"`
+	Outdated bool   `json:"outdated"`
+	Error    string `json:"error,omitempty"`
+}
+
+type OutdatedCommand struct {
+	BaseCommand
+	fs        *flag.FlagSet
+	vendorDir string
+	json      bool
+}
+
+func NewOutdatedCommand(ctx core.Context) *OutdatedCommand {
+	return &OutdatedCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (o OutdatedCommand) Description() string {
+	return "Lists installed packages that have newer versions"
+}
+
+func (o *OutdatedCommand) RegisterFlags(flags *flag.FlagSet) {
+	o.fs = flags
+
+	flags.BoolVar(&o.json, "json", false, "Print the report as JSON")
+	flags.StringVar(&o.vendorDir, "vendor-dir", "", "The directory that packages are installed in")
+}
+
+func (o *OutdatedCommand) Run() error {
+
+	pkg, err := common.LoadPackage("")
+	if err != nil && !os.IsNotExist(err) {
+		o.Error(err)
+		return err
+	}
+
+	o.vendorDir = o.resolveVendorDir(o.vendorDir, pkg)
+
+	graph, err := common.LoadDependencyGraph(pkg, o.vendorDir)
+	if err != nil {
+		o.Error(err)
+		return err
+	}
+
+	var names []string
+	for name := range graph.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := make([]*outdatedPackage, len(names))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, outdatedConcurrency)
+
+	for n, name := range names {
+		wg.Add(1)
+		go func(n int, installed *common.PackageWrapper) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			report[n] = o.check(installed, graph.Constraints(installed.Name))
+		}(n, graph.Packages[name])
+	}
+	wg.Wait()
+
+	outdated, failed := 0, 0
+	for _, p := range report {
+		if p.Outdated {
+			outdated++
+		}
+		if p.Error != "" {
+			failed++
+		}
+	}
+
+	if o.json {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			o.Error(err)
+			return err
+		}
+		fmt.Println(string(out))
+	} else {
+		o.print(report)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("could not check %d package(s)", failed)
+	}
+	if outdated > 0 {
+		return fmt.Errorf("%d package(s) are outdated", outdated)
+	}
+	return nil
+}
+
+// check compares the installed version of a package with the versions published in the
+// registry.
+func (o *OutdatedCommand) check(installed *common.PackageWrapper, constraints []string) *outdatedPackage {
+	result := &outdatedPackage{
+		Name:    installed.Name,
+		Current: installed.VersionLabel(),
+	}
+
+	info, err := o.Ctx.Client.Info(context.Background(), &msg.InfoRequest{PackageName: installed.Name})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if latest := common.LatestVersion(info.Versions); latest != nil {
+		result.Latest = latest.Label
+	}
+
+	// Wanted only makes sense if something actually asked for a range
+	for _, c := range constraints {
+		if c != "" {
+			if wanted := common.LatestVersion(info.Versions, constraints...); wanted != nil {
+				result.Wanted = wanted.Label
+			}
+			break
+		}
+	}
+
+	current, err := semver.Parse(result.Current)
+	if err != nil {
+		result.Outdated = result.Latest != "" && result.Latest != result.Current
+	} else if result.Latest != "" {
+		result.Outdated = current.LessThan(semver.MustParse(result.Latest))
+	}
+
+	return result
+}
+
+func (o *OutdatedCommand) print(report []*outdatedPackage) {
+	rows := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "Package\tCurrent\tWanted\tLatest\t")
+
+	for _, p := range report {
+		current := p.Current
+		if current == "" {
+			current = "-"
+		}
+		if p.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t\t\t%s\n", p.Name, current, p.Error)
+		} else if p.Outdated {
+			wanted := p.Wanted
+			if wanted == "" {
+				wanted = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", p.Name, current, wanted, p.Latest)
+		} else {
+			continue
+		}
+		rows++
+	}
+
+	if rows == 0 {
+		fmt.Println("All packages are up to date.")
+		return
+	}
+	w.Flush()
+}
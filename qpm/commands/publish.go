@@ -20,6 +20,7 @@ import (
 type PublishCommand struct {
 	BaseCommand
 	PackageName string
+	tokenFile   string
 }
 
 func NewPublishCommand(ctx core.Context) *PublishCommand {
@@ -35,7 +36,7 @@ func (p PublishCommand) Description() string {
 }
 
 func (p *PublishCommand) RegisterFlags(flags *flag.FlagSet) {
-
+	flags.StringVar(&p.tokenFile, "token-file", "", "Read the token used to publish from the given file instead of logging in")
 }
 
 func get(name string, echoOff bool) string {
@@ -93,13 +94,6 @@ func LoginPrompt(ctx context.Context, client msg.QpmClient) (string, error) {
 
 func (p *PublishCommand) Run() error {
 
-	token, err := LoginPrompt(context.Background(), p.Ctx.Client)
-
-	if err != nil {
-		fmt.Printf("ERROR: %v\n", err)
-		return err
-	}
-
 	fmt.Println("Running check")
 	if err := NewCheckCommand(p.Ctx).Run(); err != nil {
 		p.Fatal(err.Error())
@@ -126,8 +120,28 @@ func (p *PublishCommand) Run() error {
 		p.Fatal(err.Error())
 	}
 
+	// Lets installs check that they get the same content as what is published
+	wrapper.Version.Hash, err = hashRevision(publisher, wrapper.Version.Revision)
+	if err != nil {
+		p.Fatal("Cannot hash the package: " + err.Error())
+	}
+
+	// Log in to the registry that serves this package unless a token is available
+	client := p.Ctx.Client
+	var registry *core.Registry
+	if p.Ctx.Registries != nil {
+		registry = p.Ctx.Registries.For(wrapper.Name)
+		client = registry.Client()
+	}
+
+	token, err := publishToken(client, registry, p.tokenFile)
+	if err != nil {
+		p.Error(err)
+		return err
+	}
+
 	fmt.Println("Publishing")
-	_, err = p.Ctx.Client.Publish(context.Background(), &msg.PublishRequest{
+	_, err = client.Publish(context.Background(), &msg.PublishRequest{
 		Token:              token,
 		PackageDescription: wrapper.Package,
 	})
@@ -145,6 +159,7 @@ func (p *PublishCommand) Run() error {
 	signature := strings.Join([]string{wrapper.Name, wrapper.Version.Label}, "@")
 	fmt.Println("Publised package: " + signature)
 	fmt.Println("Revision: " + wrapper.Version.Revision)
+	fmt.Println("SHA-256: " + wrapper.Version.Hash)
 
 	return nil
 }
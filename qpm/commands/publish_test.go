@@ -0,0 +1,247 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	msg "qpm.io/common/messages"
+	qpmtesting "qpm.io/qpm/testing"
+)
+
+// checkout clones the repository of a released package so that it can be published from.
+func checkout(t *testing.T, f *qpmtesting.Fixture, pkg *msg.Package) string {
+	dir := filepath.Join(f.Dir, "checkouts", pkg.Name)
+	gitIn(t, "", "clone", "-q", "--recursive", pkg.Repository.Url, dir)
+	return dir
+}
+
+// gitIn runs git in dir as the fixture user and returns its output.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=" + qpmtesting.User}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", args[0], err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// allowFileSubmodules lets git clone submodules from local paths, which it only does when
+// asked to, until the returned function is called.
+func allowFileSubmodules() func() {
+	env := map[string]string{
+		"GIT_CONFIG_COUNT":   "1",
+		"GIT_CONFIG_KEY_0":   "protocol.file.allow",
+		"GIT_CONFIG_VALUE_0": "always",
+	}
+	saved := make(map[string]string)
+	for key, value := range env {
+		saved[key] = os.Getenv(key)
+		os.Setenv(key, value)
+	}
+	return func() {
+		for key, value := range saved {
+			os.Setenv(key, value)
+		}
+	}
+}
+
+// publishAndInstall publishes the package checked out in dir and installs it from the
+// registry into a new project, which fails unless the published hash matches the package.
+// It returns the directory of the project.
+func publishAndInstall(t *testing.T, f *qpmtesting.Fixture, dir string, name string) string {
+	client := qpmtesting.NewFakeClient()
+	server, err := qpmtesting.NewTestServer(client.Server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	login, err := client.Server.Login(context.Background(), &msg.LoginRequest{
+		Email:    qpmtesting.User,
+		Password: "secret",
+		Create:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("QPM_TOKEN", login.Token)
+
+	restore := chdir(t, dir)
+	defer stdin(t, "n\n")()
+
+	err = run(t, NewPublishCommand(qpmtesting.NewContext(server.Client)))
+	restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := f.Project("app", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	if err = run(t, NewInstallCommand(client.Context())); err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+func TestPublish(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	client := qpmtesting.NewFakeClient()
+	server, err := qpmtesting.NewTestServer(client.Server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	login, err := client.Server.Login(context.Background(), &msg.LoginRequest{
+		Email:    qpmtesting.User,
+		Password: "secret",
+		Create:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("QPM_TOKEN", login.Token)
+
+	gamma, err := f.Release("io.qpm.gamma", "0.1.0", nil, "LICENSE", "MIT\n", "gamma.h", "int gamma;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	restore := chdir(t, checkout(t, f, gamma))
+	defer stdin(t, "n\n")()
+
+	err = run(t, NewPublishCommand(qpmtesting.NewContext(server.Client)))
+	restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := server.Client.Info(context.Background(), &msg.InfoRequest{PackageName: "io.qpm.gamma"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Versions) != 1 || info.Versions[0].Version.Revision != gamma.Version.Revision {
+		t.Fatalf("expected revision %s to be published, got %v", gamma.Version.Revision, info.Versions)
+	}
+
+	// The published hash must match what install gets
+	project, err := f.Project("app", "io.qpm.gamma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	if err = run(t, NewInstallCommand(client.Context())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishIgnoresUncommittedChanges(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	gamma, err := f.Release("io.qpm.gamma", "0.1.0", nil, "LICENSE", "MIT\n", "gamma.h", "int gamma;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := checkout(t, f, gamma)
+	for name, content := range map[string]string{"gamma.h": "int changed;\n", "notes.txt": "not committed\n"} {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publishAndInstall(t, f, dir, "io.qpm.gamma")
+}
+
+func TestPublishIgnoresExportAttributes(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	// git archive would drop and rewrite these files, but install checks them out as they are
+	gamma, err := f.Release("io.qpm.gamma", "0.1.0", nil,
+		"LICENSE", "MIT\n",
+		".gitattributes", "gamma.h export-subst\nNOTES export-ignore\n",
+		"gamma.h", "// $Format:%H$\nint gamma;\n",
+		"NOTES", "Not for release\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publishAndInstall(t, f, checkout(t, f, gamma), "io.qpm.gamma")
+}
+
+func TestPublishIncludesSubmodules(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	defer allowFileSubmodules()()
+
+	delta, err := f.Release("io.qpm.delta", "1.0.0", nil, "delta.h", "int delta;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gamma, err := f.Release("io.qpm.gamma", "0.1.0", nil, "LICENSE", "MIT\n", "gamma.h", "int gamma;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, gamma.Repository.Url, "submodule", "add", "-q", delta.Repository.Url, "third_party/delta")
+	gitIn(t, gamma.Repository.Url, "commit", "-q", "-m", "Add delta")
+
+	publishAndInstall(t, f, checkout(t, f, gamma), "io.qpm.gamma")
+}
+
+func TestPublishRevisionWithChangedSubmodule(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+	defer allowFileSubmodules()()
+
+	delta, err := f.Release("io.qpm.delta", "1.0.0", nil, "delta.h", "int delta;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gamma, err := f.Release("io.qpm.gamma", "0.1.0", nil, "LICENSE", "MIT\n", "gamma.h", "int gamma;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, gamma.Repository.Url, "submodule", "add", "-q", delta.Repository.Url, "third_party/delta")
+	gitIn(t, gamma.Repository.Url, "commit", "-q", "-m", "Add delta")
+	revision := gitIn(t, gamma.Repository.Url, "rev-parse", "HEAD")
+
+	// Move the submodule on in a later commit, which the default branch then points at
+	if _, err = f.Release("io.qpm.delta", "1.1.0", nil, "delta.h", "int delta2;\n"); err != nil {
+		t.Fatal(err)
+	}
+	submodule := filepath.Join(gamma.Repository.Url, "third_party", "delta")
+	gitIn(t, submodule, "fetch", "-q")
+	gitIn(t, submodule, "checkout", "-q", "FETCH_HEAD")
+	gitIn(t, gamma.Repository.Url, "commit", "-q", "-a", "-m", "Update delta")
+
+	dir := checkout(t, f, gamma)
+	gitIn(t, dir, "checkout", "-q", revision)
+	gitIn(t, dir, "submodule", "update", "-q", "--init", "--recursive")
+
+	project := publishAndInstall(t, f, dir, "io.qpm.gamma")
+
+	content, err := ioutil.ReadFile(filepath.Join(project, "vendor", "io", "qpm", "gamma", "third_party", "delta", "delta.h"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "int delta;\n" {
+		t.Errorf("expected the submodule at the published revision, got %q", content)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+var qbsFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+}
+
+var (
+	vendorQbs = template.Must(template.New("vendorQbs").Funcs(qbsFuncs).Parse(`// Generated by qpm, do not edit.
+//
+// Project {
+//     references: ["vendor/qpm.qbs"]
+//     CppApplication {
+//         Depends { name: "qpm" }
+//         qmlImportPaths: qpm.qmlImportPaths
+//     }
+// }
+
+import qbs
+
+Product {
+    name: "qpm"
+
+    Export {
+        Depends { name: "cpp" }
+        Depends { name: "Qt.core" }
+
+        property stringList qmlImportPaths: [exportingProduct.sourceDirectory]
+
+        cpp.includePaths: [
+            exportingProduct.sourceDirectory{{range .IncludePaths}},
+            exportingProduct.sourceDirectory + {{quote .}}{{end}}
+        ]
+        cpp.defines: [
+            'QPM_INIT(E)=E.addImportPath(QStringLiteral("qrc:/"));',
+            "QPM_USE_NS"
+        ]
+{{range .Packages}}
+        Group {
+            name: {{quote .Name}}
+            prefix: exportingProduct.sourceDirectory + "/"
+            files: [{{range $i, $file := .Files}}{{if $i}},{{end}}
+                {{quote $file}}{{end}}
+            ]
+        }
+{{end}}    }
+}
+`))
+)
+
+type qbsPackage struct {
+	Name  string
+	Files []string
+}
+
+func init() {
+	core.RegisterGenerator("qbs", qbsGenerator{})
+}
+
+// qbsGenerator writes a qpm.qbs inside the vendor directory with a Qbs product that exports
+// the sources, resources, include paths and QML import paths of the installed packages.
+type qbsGenerator struct{}
+
+func (qbsGenerator) Generate(vendorDir string, root *msg.Package, dependencies []*msg.Package) error {
+	data := struct {
+		IncludePaths []string
+		Packages     []qbsPackage
+	}{}
+
+	for _, d := range dependencies {
+		dep := wrapPackage(vendorDir, d)
+		sources, resources, err := packageFiles(vendorDir, dep)
+		if err != nil {
+			return err
+		}
+		data.IncludePaths = append(data.IncludePaths, "/"+relPath(vendorDir, dep.RootDir()))
+		data.Packages = append(data.Packages, qbsPackage{
+			Name:  dep.Name,
+			Files: append(sources, resources...),
+		})
+	}
+
+	vendorQbsFile := filepath.Join(vendorDir, "qpm.qbs")
+
+	return core.WriteTemplate(vendorQbsFile, vendorQbs, data)
+}
@@ -0,0 +1,69 @@
+// Copyright 2015 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"path/filepath"
+	"text/template"
+
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+var packageFuncs = template.FuncMap{
+	"relPriFile": func(vendorDir string, dep *common.PackageWrapper) string {
+		abs := filepath.Join(dep.RootDir(), dep.PriFile())
+		rel, err := filepath.Rel(vendorDir, abs)
+		if err == nil {
+			return rel
+		} else {
+			return abs
+		}
+	},
+}
+
+var (
+	// This template is very dense to avoid excessive whitespace in the generated code.
+	// We can address this in a future version of Go (1.6?):
+	// https://github.com/golang/go/commit/e6ee26a03b79d0e8b658463bdb29349ca68e1460
+	vendorPri = template.Must(template.New("vendorPri").Funcs(packageFuncs).Parse(`
+DEFINES += QPM_INIT\\(E\\)=\"E.addImportPath(QStringLiteral(\\\"qrc:/\\\"));\"
+DEFINES += QPM_USE_NS
+INCLUDEPATH += $$PWD
+QML_IMPORT_PATH += $$PWD
+{{$vendirDir := .VendorDir}}
+{{range $dep := .Dependencies}}
+include($$PWD/{{relPriFile $vendirDir $dep}}){{end}}
+`))
+)
+
+func init() {
+	core.RegisterGenerator("qmake", qmakeGenerator{})
+}
+
+// qmakeGenerator writes a vendor.pri inside the vendor directory which includes the .pri
+// file of every installed package.
+type qmakeGenerator struct{}
+
+func (qmakeGenerator) Generate(vendorDir string, root *msg.Package, dependencies []*msg.Package) error {
+	var deps []*common.PackageWrapper
+	for _, dep := range dependencies {
+		deps = append(deps, wrapPackage(vendorDir, dep))
+	}
+
+	vendorPriFile := filepath.Join(vendorDir, core.Vendor+".pri")
+
+	data := struct {
+		VendorDir    string
+		Package      *msg.Package
+		Dependencies []*common.PackageWrapper
+	}{
+		vendorDir,
+		root,
+		deps,
+	}
+
+	return core.WriteTemplate(vendorPriFile, vendorPri, data)
+}
@@ -5,28 +5,26 @@ package commands
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"encoding/hex"
 	"flag"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"golang.org/x/crypto/openpgp"
 	"golang.org/x/crypto/openpgp/packet"
 	"qpm.io/common"
-	msg "qpm.io/common/messages"
 	"qpm.io/qpm/core"
 	"qpm.io/qpm/vcs"
 )
 
 type SignCommand struct {
 	BaseCommand
-	pkg   *common.PackageWrapper
-	paths []string
+	pkg     *common.PackageWrapper
+	paths   []string
+	keyFile string
 }
 
 func NewSignCommand(ctx core.Context) *SignCommand {
@@ -42,7 +40,7 @@ func (s SignCommand) Description() string {
 }
 
 func (s *SignCommand) RegisterFlags(flags *flag.FlagSet) {
-
+	flags.StringVar(&s.keyFile, "key", "", "Read the private key from an armored key file instead of secring.gpg in GNUPGHOME")
 }
 
 func (s *SignCommand) Run() error {
@@ -54,9 +52,19 @@ func (s *SignCommand) Run() error {
 		return err
 	}
 
-	// Hash the repo
+	// Hash the last commit, which is what publish hashes
 
-	hash, err := hashRepo(s.pkg.Repository)
+	publisher, err := vcs.CreatePublisher(s.pkg.Repository)
+	if err != nil {
+		s.Error(err)
+		return err
+	}
+	revision, err := publisher.LastCommitRevision()
+	if err != nil {
+		s.Error(err)
+		return err
+	}
+	hash, err := hashRevision(publisher, revision)
 	if err != nil {
 		s.Error(err)
 		return err
@@ -65,15 +73,19 @@ func (s *SignCommand) Run() error {
 
 	// Sign the SHA
 
-	fmt.Println("Loading the GnuPG private key")
+	fmt.Println("Loading the private key")
 
-	if s.pkg.Version.Fingerprint == "" {
-		err = fmt.Errorf("no fingerprint set in " + core.PackageFile)
+	fingerprint := s.pkg.Version.Fingerprint
+	if fingerprint == "" {
+		fingerprint = s.Ctx.Config.SigningKey
+	}
+	if fingerprint == "" {
+		err = fmt.Errorf("no fingerprint set in " + core.PackageFile + " or the signingKey setting")
 		s.Error(err)
 		return err
 	}
 
-	signer, err := entityFromLocal("secring.gpg", s.pkg.Version.Fingerprint)
+	signer, err := secretKey(s.keyFile, fingerprint)
 	if err != nil {
 		s.Error(err)
 		return err
@@ -105,106 +117,113 @@ func (s *SignCommand) Run() error {
 	// Verify the signature
 
 	fmt.Println("Verifying the signature")
-	entity, err := entityFromLocal("pubring.gpg", s.pkg.Version.Fingerprint)
+	err = Verify(hash, sig, signer.PrimaryKey)
 	if err != nil {
 		s.Error(err)
 		return err
 	}
 
-	err = Verify(hash, sig, entity.PrimaryKey)
+	keyring, err := common.LoadKeyring()
 	if err != nil {
 		s.Error(err)
 		return err
 	}
+	if key := keyring.Find(fingerprint); key == nil || !key.Trusts(s.pkg.Name) {
+		s.Warning(fmt.Sprintf("%s is not trusted for %s in the qpm keyring, so installs on this machine cannot verify the package. "+
+			"Use 'qpm keys import' and 'qpm keys trust' to trust it.", fingerprint, s.pkg.Name))
+	}
 
 	fmt.Println("Done")
 
 	return nil
 }
 
-// SHA-256 hashing
-
-func hash(path string) ([]byte, error) {
+// hashRevision hashes the files committed in revision the same way that install hashes the
+// package once it has checked out that revision, so uncommitted changes are left out.
+func hashRevision(publisher vcs.Publisher, revision string) (string, error) {
 
-	var result []byte
-	file, err := os.Open(path)
+	dir, err := ioutil.TempDir("", "qpm-publish-")
 	if err != nil {
-		return result, err
+		return "", err
 	}
-	defer file.Close()
+	defer os.RemoveAll(dir)
 
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return result, err
+	if err = publisher.Export(revision, dir); err != nil {
+		return "", err
 	}
 
-	return hash.Sum(result), nil
+	return common.HashTree(dir)
 }
 
-func HashPaths(paths []string) (string, error) {
-
-	var result []byte
-
-	// we need to sort to get consistent results
-	sort.Strings(paths)
+// PGP signing
 
-	master := sha256.New()
+func decryptEntity(entity *openpgp.Entity) error {
 
-	for _, p := range paths {
-		f, err := os.Stat(p)
-		if err != nil {
-			return "", err
-		}
-		if strings.HasSuffix(p, core.SignatureFile) {
-			continue
-		}
-		if !f.IsDir() {
-			sha, err := hash(p)
-			if err != nil {
-				fmt.Println(err)
-				return "", err
-			}
-			master.Write(sha)
-		}
+	passwd := <-PromptPassword("Password:")
+	err := entity.PrivateKey.Decrypt([]byte(passwd))
+	if err == nil {
+		return nil
 	}
-
-	result = master.Sum(nil)
-
-	return hex.EncodeToString(result), nil
+	return nil
 }
 
-func hashRepo(repository *msg.Package_Repository) (string, error) {
+// secretKey returns the private key with the given fingerprint from an armored key file,
+// such as the output of gpg --export-secret-keys --armor. If file is empty, the key is read
+// from secring.gpg in GNUPGHOME, which older versions of GnuPG use.
+func secretKey(file string, fingerprint string) (*openpgp.Entity, error) {
 
-	publisher, err := vcs.CreatePublisher(repository)
+	var keyring openpgp.EntityList
+	var err error
+	if file == "" {
+		file = "secring.gpg"
+		keyring, err = readKeyRing(file)
+	} else {
+		keyring, err = readArmoredKeyRing(file)
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	paths, err := publisher.RepositoryFileList()
+	entity, err := findEntity(keyring, fingerprint)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%v in %s", err, file)
 	}
 
-	return HashPaths(paths)
-}
+	if entity.PrivateKey == nil {
+		return nil, fmt.Errorf("%s does not contain the private key for %s", file, fingerprint)
+	}
 
-// PGP signing
+	if entity.PrivateKey.Encrypted {
+		if err := decryptEntity(entity); err != nil {
+			return nil, err
+		}
+	}
+	return entity, nil
+}
 
-func decryptEntity(entity *openpgp.Entity) error {
+// readArmoredKeyRing reads the armored keys in the given file.
+func readArmoredKeyRing(fileName string) (openpgp.EntityList, error) {
 
-	passwd := <-PromptPassword("Password:")
-	err := entity.PrivateKey.Decrypt([]byte(passwd))
-	if err == nil {
-		return nil
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	defer file.Close()
+
+	return openpgp.ReadArmoredKeyRing(file)
 }
 
-func entityFromLocal(fileName string, fingerprint string) (*openpgp.Entity, error) {
+// readKeyRing reads the GnuPG keyring with the given name from GNUPGHOME, or ~/.gnupg if it
+// is not set.
+func readKeyRing(fileName string) (openpgp.EntityList, error) {
 
 	path := os.Getenv("GNUPGHOME")
 	if len(path) == 0 {
-		return nil, fmt.Errorf("cound not find GNUPGHOME in ENV")
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("cound not find GNUPGHOME in ENV")
+		}
+		path = filepath.Join(home, ".gnupg")
 	}
 
 	file, err := os.Open(filepath.Join(path, fileName))
@@ -213,12 +232,13 @@ func entityFromLocal(fileName string, fingerprint string) (*openpgp.Entity, erro
 	}
 	defer file.Close()
 
-	keyring, err := openpgp.ReadKeyRing(file)
-	if err != nil {
-		return nil, err
-	}
+	return openpgp.ReadKeyRing(file)
+}
+
+// findEntity returns the key in the keyring with the given hex encoded fingerprint.
+func findEntity(keyring openpgp.EntityList, fingerprint string) (*openpgp.Entity, error) {
 
-	decoded, err := hex.DecodeString(fingerprint)
+	decoded, err := hex.DecodeString(common.NormalizeFingerprint(fingerprint))
 	if err != nil {
 		return nil, err
 	}
@@ -231,21 +251,12 @@ func entityFromLocal(fileName string, fingerprint string) (*openpgp.Entity, erro
 	copy(fp[:], decoded[:20])
 
 	for _, entity := range keyring {
-
-		if entity.PrimaryKey.Fingerprint != fp {
-			continue
-		}
-
-		if entity != nil && entity.PrivateKey != nil && entity.PrivateKey.Encrypted {
-			if err := decryptEntity(entity); err != nil {
-				return nil, err
-			}
+		if entity.PrimaryKey.Fingerprint == fp {
 			return entity, nil
 		}
-		return entity, nil
 	}
 
-	return nil, fmt.Errorf("entity for %s not found in %s", fingerprint, fileName)
+	return nil, fmt.Errorf("entity for %s not found", fingerprint)
 }
 
 func Sign(unsigned string, signer *openpgp.Entity) ([]byte, error) {
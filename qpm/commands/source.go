@@ -0,0 +1,32 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"golang.org/x/net/context"
+	msg "qpm.io/common/messages"
+)
+
+// registrySource provides the package information used by common.Resolver from the server.
+type registrySource struct {
+	client msg.QpmClient
+}
+
+func newRegistrySource(client msg.QpmClient) *registrySource {
+	return &registrySource{
+		client: client,
+	}
+}
+
+func (r *registrySource) Info(name string) (*msg.InfoResponse, error) {
+	return r.client.Info(context.Background(), &msg.InfoRequest{PackageName: name})
+}
+
+// Dependencies returns the dependencies declared in the package file of the given release.
+// The registry only returns the package file of the latest release, so older releases are
+// assumed to declare the same dependencies and are reported as not exact.
+func (r *registrySource) Dependencies(info *msg.InfoResponse, version *msg.Package_Version) ([]string, bool, error) {
+	exact := info.Package.Version != nil && info.Package.Version.Label == version.Label
+	return info.Package.Dependencies, exact, nil
+}
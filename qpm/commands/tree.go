@@ -0,0 +1,210 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"qpm.io/common"
+	"qpm.io/qpm/core"
+)
+
+type treeNode struct {
+	Name         string      `json:"name"`
+	Version      string      `json:"version,omitempty"`
+	Requested    string      `json:"requested,omitempty"`
+	Missing      bool        `json:"missing,omitempty"`
+	Invalid      bool        `json:"invalid,omitempty"`
+	Duplicate    bool        `json:"duplicate,omitempty"`
+	Dependencies []*treeNode `json:"dependencies,omitempty"`
+}
+
+func (n treeNode) String() string {
+	label := n.Name
+	if n.Missing {
+		if n.Requested != "" {
+			label += "@" + n.Requested
+		}
+		return label + " (missing)"
+	}
+	if n.Version != "" {
+		label += "@" + n.Version
+	}
+	if n.Invalid && n.Version == "" {
+		label += " (unknown version)"
+	} else if n.Invalid {
+		label += " (invalid: requires " + n.Requested + ")"
+	}
+	if n.Duplicate {
+		label += " (*)"
+	}
+	return label
+}
+
+type dotItem struct {
+	name string
+	pkg  *common.PackageWrapper
+}
+
+type TreeCommand struct {
+	BaseCommand
+	fs        *flag.FlagSet
+	vendorDir string
+	json      bool
+	dot       bool
+}
+
+func NewTreeCommand(ctx core.Context) *TreeCommand {
+	return &TreeCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (t TreeCommand) Description() string {
+	return "Prints the tree of installed dependencies"
+}
+
+func (t *TreeCommand) RegisterFlags(flags *flag.FlagSet) {
+	t.fs = flags
+
+	flags.BoolVar(&t.json, "json", false, "Print the tree as JSON")
+	flags.BoolVar(&t.dot, "dot", false, "Print the graph in Graphviz dot format")
+	flags.StringVar(&t.vendorDir, "vendor-dir", "", "The directory that packages are installed in")
+}
+
+func (t *TreeCommand) Run() error {
+
+	pkg, err := common.LoadPackage("")
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("No %s file found", core.PackageFile)
+		}
+		t.Error(err)
+		return err
+	}
+
+	t.vendorDir = t.resolveVendorDir(t.vendorDir, pkg)
+
+	graph, err := common.LoadDependencyGraph(pkg, t.vendorDir)
+	if err != nil {
+		t.Error(err)
+		return err
+	}
+
+	if t.dot {
+		t.printDot(graph)
+		return nil
+	}
+
+	root := &treeNode{Name: graph.RootName(), Version: pkg.VersionLabel()}
+	root.Dependencies = t.children(graph, pkg, map[string]bool{})
+
+	if t.json {
+		out, err := json.MarshalIndent(root, "", "  ")
+		if err != nil {
+			t.Error(err)
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Println(root)
+	t.printNodes(root.Dependencies, "")
+
+	return nil
+}
+
+// children builds the nodes for the dependencies of pkg. Packages that have already been
+// expanded elsewhere in the tree are marked as duplicates instead of being expanded again.
+func (t *TreeCommand) children(graph *common.DependencyGraph, pkg *common.PackageWrapper, seen map[string]bool) []*treeNode {
+	nodes := []*treeNode{}
+	for _, e := range graph.Edges(pkg) {
+		node := &treeNode{
+			Name:      e.Name,
+			Requested: e.Constraint,
+			Missing:   e.Missing(),
+		}
+		if !e.Missing() {
+			node.Version = e.Package.VersionLabel()
+			node.Invalid = !e.Satisfied()
+			if seen[e.Name] {
+				node.Duplicate = true
+			} else {
+				seen[e.Name] = true
+				node.Dependencies = t.children(graph, e.Package, seen)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func (t *TreeCommand) printNodes(nodes []*treeNode, indent string) {
+	for i, n := range nodes {
+		branch, next := "|-- ", "|   "
+		if i == len(nodes)-1 {
+			branch, next = "`-- ", "    "
+		}
+		fmt.Println(indent + branch + n.String())
+		t.printNodes(n.Dependencies, indent+next)
+	}
+}
+
+func (t *TreeCommand) printDot(graph *common.DependencyGraph) {
+	fmt.Println("digraph dependencies {")
+
+	rootName := graph.RootName()
+	fmt.Printf("\t%q [shape=box];\n", rootName)
+
+	// Visit every package reachable from the root in a stable order
+	visited := map[string]bool{}
+	queue := []dotItem{{rootName, graph.Root}}
+
+	var nodes []string
+	nodeLabels := map[string]string{}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, e := range graph.Edges(current.pkg) {
+			label := e.Constraint
+			if label == "" {
+				label = "*"
+			}
+			attrs := fmt.Sprintf("label=%q", label)
+			if !e.Missing() && !e.Satisfied() {
+				attrs += ", color=red"
+			}
+			fmt.Printf("\t%q -> %q [%s];\n", current.name, e.Name, attrs)
+
+			if visited[e.Name] {
+				continue
+			}
+			visited[e.Name] = true
+			nodes = append(nodes, e.Name)
+
+			if e.Missing() {
+				nodeLabels[e.Name] = fmt.Sprintf("label=%q, style=dashed", e.Name+"\nmissing")
+			} else {
+				nodeLabels[e.Name] = fmt.Sprintf("label=%q", e.Name+"\n"+e.Package.VersionLabel())
+				queue = append(queue, dotItem{e.Name, e.Package})
+			}
+		}
+	}
+
+	sort.Strings(nodes)
+	for _, n := range nodes {
+		fmt.Printf("\t%q [%s];\n", n, nodeLabels[n])
+	}
+
+	fmt.Println("}")
+}
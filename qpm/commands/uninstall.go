@@ -9,7 +9,6 @@ import (
 	"io"
 	"os"
 	"path"
-	"path/filepath"
 
 	"qpm.io/common"
 	"qpm.io/qpm/core"
@@ -36,14 +35,10 @@ func (u UninstallCommand) Description() string {
 func (u *UninstallCommand) RegisterFlags(flags *flag.FlagSet) {
 	u.fs = flags
 
-	var err error
-	u.vendorDir, err = filepath.Abs(core.Vendor)
-	if err != nil {
-		u.vendorDir = core.Vendor
-	}
+	flags.StringVar(&u.vendorDir, "vendor-dir", "", "The directory that packages are installed in")
 }
 
-func (u UninstallCommand) isEmpty(name string) (error, bool) {
+func isEmpty(name string) (error, bool) {
 	f, err := os.Open(name)
 	if err != nil {
 		return err, false
@@ -67,6 +62,15 @@ func (u *UninstallCommand) Run() error {
 		return err
 	}
 
+	// Does the current directory contain a package file that needs updating?
+	pkg, pkgErr := common.LoadPackage("")
+	if pkgErr != nil && !os.IsNotExist(pkgErr) {
+		u.Error(pkgErr)
+		return pkgErr
+	}
+
+	u.vendorDir = u.resolveVendorDir(u.vendorDir, pkg)
+
 	dependencyMap, err := common.LoadPackages(u.vendorDir)
 	if err != nil {
 		u.Error(err)
@@ -80,17 +84,34 @@ func (u *UninstallCommand) Run() error {
 		return err
 	}
 
-	// Does the current directory contain a package file that needs updating?
-	pkg, err := common.LoadPackage("")
-	if err != nil && !os.IsNotExist(err) {
-		u.Error(err)
-		return err
-	} else if err == nil {
+	if pkgErr == nil {
+		lock, err := common.LoadLockFile("")
+		if err != nil && !os.IsNotExist(err) {
+			u.Error(err)
+			return err
+		}
+		lockExists := err == nil
+		lockMatches := lockExists && lock.Matches(pkg)
+
 		pkg.RemoveDependency(toRemove)
 		if err := pkg.Save(); err != nil {
 			u.Error(err)
 			return err
 		}
+
+		// Keep the lock file in step with the package file
+		if lockExists {
+			lock.Remove(toRemove.Name)
+			if lockMatches {
+				lock.PackageHash = common.DependencyHash(pkg.Dependencies)
+			} else {
+				lock.PackageHash = ""
+			}
+			if err := lock.Save(); err != nil {
+				u.Error(err)
+				return err
+			}
+		}
 	}
 
 	fmt.Println("Uninstalling", toRemove.Name)
@@ -99,25 +120,34 @@ func (u *UninstallCommand) Run() error {
 	// be done last since after this step, the info about the package is
 	// gone.
 
-	if err := os.RemoveAll(toRemove.RootDir()); err != nil {
+	if err := removePackageDir(u.vendorDir, toRemove.RootDir()); err != nil {
 		u.Error(err)
 		return err
 	}
 
-	// Cleanup empty leaf directories in parent dirs
-	dir := path.Clean(toRemove.RootDir() + "/..")
-	for dir != u.vendorDir {
-		if _, empty := u.isEmpty(dir); empty {
-			os.Remove(dir)
-		}
-		dir = path.Clean(dir + "/..")
+	// Regenerate vendor.pri and the other build files
+	if err := generateBuildFiles(u.vendorDir, pkg); err != nil {
+		u.Error(err)
+		return err
 	}
 
-	// Regenerate vendor.pri
-	if err := GenerateVendorPri(u.vendorDir, pkg); err != nil {
-		u.Error(err)
+	return nil
+}
+
+// removePackageDir deletes the directory of an installed package and then any parent
+// directories inside vendorDir that are left empty.
+func removePackageDir(vendorDir string, dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
 		return err
 	}
 
+	// Cleanup empty leaf directories in parent dirs
+	dir = path.Clean(dir + "/..")
+	for dir != vendorDir {
+		if _, empty := isEmpty(dir); empty {
+			os.Remove(dir)
+		}
+		dir = path.Clean(dir + "/..")
+	}
 	return nil
 }
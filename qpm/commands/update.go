@@ -0,0 +1,286 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/common/semver"
+	"qpm.io/qpm/core"
+)
+
+type UpdateCommand struct {
+	BaseCommand
+	fs        *flag.FlagSet
+	vendorDir string
+	dryRun    bool
+	jobs      int
+}
+
+func NewUpdateCommand(ctx core.Context) *UpdateCommand {
+	return &UpdateCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (u UpdateCommand) Description() string {
+	return "Updates packages to the newest allowed version"
+}
+
+func (u *UpdateCommand) RegisterFlags(flags *flag.FlagSet) {
+	u.fs = flags
+
+	flags.BoolVar(&u.dryRun, "dry-run", false, "Print the planned changes without applying them")
+	flags.IntVar(&u.jobs, "jobs", defaultJobs, "The number of packages to install at the same time")
+	flags.StringVar(&u.vendorDir, "vendor-dir", "", "The directory that packages are installed in")
+}
+
+// relax widens an exact version in the package file so that newer compatible versions are
+// allowed. Ranges are left as they are.
+func relax(constraint string) string {
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return constraint
+	}
+	if exact := c.Exact(); exact != nil {
+		return "^" + exact.String()
+	}
+	return constraint
+}
+
+func (u *UpdateCommand) Run() error {
+
+	pkg, err := common.LoadPackage("")
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("No %s file found", core.PackageFile)
+		}
+		u.Error(err)
+		return err
+	}
+
+	u.vendorDir = u.resolveVendorDir(u.vendorDir, pkg)
+
+	graph, err := common.LoadDependencyGraph(pkg, u.vendorDir)
+	if err != nil {
+		u.Error(err)
+		return err
+	}
+
+	rootDeps := pkg.ParseDependencies()
+
+	// Work out which packages should move to a newer version
+	targets := make(map[string]bool)
+	for _, arg := range u.fs.Args() {
+		name, _ := common.ParseDependency(arg)
+		_, direct := rootDeps[name]
+		_, installed := graph.Packages[name]
+		if !direct && !installed {
+			err = fmt.Errorf("%s is not a dependency", name)
+			u.Error(err)
+			return err
+		}
+		targets[name] = true
+	}
+	if len(targets) == 0 {
+		for name := range rootDeps {
+			targets[name] = true
+		}
+	}
+
+	// Everything else should stay at the installed version if possible
+	resolver := common.NewResolver(newRegistrySource(u.Ctx.Client))
+	for name, installed := range graph.Packages {
+		if !targets[name] && installed.VersionLabel() != "" {
+			resolver.Hint(name, installed.VersionLabel())
+		}
+	}
+
+	var requested []string
+	for _, d := range pkg.Dependencies {
+		name, constraint := common.ParseDependency(d)
+		if targets[name] {
+			constraint = relax(constraint)
+		}
+		if constraint != "" {
+			name += "@" + constraint
+		}
+		requested = append(requested, name)
+	}
+
+	resolution, err := resolver.Resolve(graph.RootName(), requested)
+	if err != nil {
+		u.Error(err)
+		return err
+	}
+
+	// Compare the resolution with what is installed
+	var changes []*msg.Dependency
+	for _, d := range resolution.Dependencies {
+		installed, ok := graph.Packages[d.Name]
+		if ok && installed.VersionLabel() == d.Version.Label {
+			continue
+		}
+		from := "(not installed)"
+		if ok {
+			from = installed.VersionLabel()
+			if from == "" {
+				from = "(unknown version)"
+			}
+		}
+		fmt.Printf("%s %s -> %s\n", d.Name, from, d.Version.Label)
+		changes = append(changes, d)
+	}
+
+	// Packages that are installed but no longer part of the tree
+	resolved := make(map[string]bool)
+	for _, d := range resolution.Dependencies {
+		resolved[d.Name] = true
+	}
+	var removed []*common.PackageWrapper
+	for name, installed := range graph.Packages {
+		if !resolved[name] {
+			removed = append(removed, installed)
+		}
+	}
+	sort.Sort(packagesByName(removed))
+	for _, r := range removed {
+		fmt.Printf("%s %s -> (removed)\n", r.Name, r.VersionLabel())
+	}
+
+	if len(changes) == 0 && len(removed) == 0 {
+		fmt.Println("All packages are up to date.")
+		return nil
+	}
+
+	if u.dryRun {
+		return nil
+	}
+
+	// Packages nested inside a package that changes are removed along with its directory,
+	// so they have to be installed again
+	changed := make(map[string]bool)
+	for _, c := range changes {
+		changed[c.Name] = true
+	}
+	for _, d := range resolution.Dependencies {
+		for _, c := range changes {
+			if !changed[d.Name] && nestedIn(c.Name, d.Name) {
+				changes = append(changes, d)
+				changed[d.Name] = true
+				break
+			}
+		}
+	}
+
+	// Install the new versions
+	lock, err := common.LoadLockFile("")
+	if err != nil && !os.IsNotExist(err) {
+		u.Error(err)
+		return err
+	}
+
+	installer := NewInstallCommand(u.Ctx)
+	installer.pkg = pkg
+	installer.lock = lock
+	installer.vendorDir = u.vendorDir
+
+	if err = os.MkdirAll(u.vendorDir, 0755); err != nil {
+		u.Error(err)
+		return err
+	}
+
+	installer.jobs = u.jobs
+
+	if err := installer.loadTrustedKeys(); err != nil {
+		return err
+	}
+
+	if _, err := installer.installAll(changes); err != nil {
+		return err
+	}
+
+	for _, r := range removed {
+		if err := u.remove(r, resolution); err != nil {
+			u.Error(err)
+			return err
+		}
+	}
+
+	// Rewrite the exact versions in the package file
+	versions := make(map[string]string)
+	for _, d := range resolution.Dependencies {
+		versions[d.Name] = d.Version.Label
+	}
+	for n, d := range pkg.Dependencies {
+		name, constraint := common.ParseDependency(d)
+		if !targets[name] || constraint == relax(constraint) {
+			continue
+		}
+		if label, ok := versions[name]; ok {
+			pkg.Dependencies[n] = strings.Join([]string{name, label}, "@")
+		}
+	}
+
+	if err := pkg.Save(); err != nil {
+		u.Error(err)
+		return err
+	}
+
+	// Drop anything from the lock that is no longer part of the tree and make sure that
+	// the packages which did not change are in it
+	locked := []*common.LockedDependency{}
+	for _, l := range lock.Dependencies {
+		if _, ok := versions[l.Name]; ok {
+			locked = append(locked, l)
+		}
+	}
+	lock.Dependencies = locked
+
+	for _, d := range resolution.Dependencies {
+		if l := lock.Find(d.Name); l != nil && l.Revision == d.Version.Revision {
+			continue
+		}
+		if installed, ok := graph.Packages[d.Name]; ok {
+			hash, err := common.HashTree(installed.RootDir())
+			if err != nil {
+				u.Error(err)
+				return err
+			}
+			lock.Put(common.NewLockedDependency(d, hash))
+		}
+	}
+
+	if err := installer.saveLock(false, true); err != nil {
+		return err
+	}
+
+	return installer.postInstall()
+}
+
+// remove deletes a package that is no longer needed from the vendor directory, unless a
+// package that is still needed is installed inside it.
+func (u *UpdateCommand) remove(pkg *common.PackageWrapper, resolution *common.Resolution) error {
+	for _, d := range resolution.Dependencies {
+		if nestedIn(pkg.Name, d.Name) {
+			u.Warning(fmt.Sprintf("%s is no longer needed but is left in place since %s is installed inside it", pkg.Name, d.Name))
+			return nil
+		}
+	}
+	return removePackageDir(u.vendorDir, pkg.RootDir())
+}
+
+type packagesByName []*common.PackageWrapper
+
+func (p packagesByName) Len() int           { return len(p) }
+func (p packagesByName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p packagesByName) Less(i, j int) bool { return p[i].Name < p[j].Name }
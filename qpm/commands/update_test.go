@@ -0,0 +1,70 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	msg "qpm.io/common/messages"
+	qpmtesting "qpm.io/qpm/testing"
+)
+
+func TestUpdateRemovesUnusedPackages(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	client := qpmtesting.NewFakeClient()
+	alpha, err := f.Release("io.qpm.alpha", "1.0.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, err := f.Release("io.qpm.beta", "1.0.0", []string{"io.qpm.alpha@^1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Add(alpha, beta); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := f.Project("app", "io.qpm.beta@^1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	if err = run(t, NewInstallCommand(client.Context())); err != nil {
+		t.Fatal(err)
+	}
+
+	// Install records alpha in the package file, so drop it there as well as in beta
+	beta, err = f.Release("io.qpm.beta", "1.1.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Add(beta); err != nil {
+		t.Fatal(err)
+	}
+	if err = qpmtesting.WritePackage(project, &msg.Package{Dependencies: []string{"io.qpm.beta@^1.0"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = run(t, NewUpdateCommand(client.Context())); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(project, "vendor", "io", "qpm", "alpha")); !os.IsNotExist(err) {
+		t.Errorf("io.qpm.alpha was not removed: %v", err)
+	}
+	pri, err := ioutil.ReadFile(filepath.Join(project, "vendor", "vendor.pri"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(pri), "alpha") {
+		t.Errorf("vendor.pri still includes io.qpm.alpha:\n%s", pri)
+	}
+}
@@ -10,19 +10,21 @@ import (
 	"fmt"
 	"golang.org/x/crypto/openpgp/armor"
 	"golang.org/x/crypto/openpgp/packet"
+	"golang.org/x/net/context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"qpm.io/common"
+	msg "qpm.io/common/messages"
 	"qpm.io/qpm/core"
-	"strings"
+	"sync"
 )
 
 type VerifyCommand struct {
 	BaseCommand
-	pkg   *common.PackageWrapper
-	fs    *flag.FlagSet
-	paths []string
+	pkg       *common.PackageWrapper
+	fs        *flag.FlagSet
+	vendorDir string
 }
 
 func NewVerifyCommand(ctx core.Context) *VerifyCommand {
@@ -39,20 +41,27 @@ func (v VerifyCommand) Description() string {
 
 func (v *VerifyCommand) RegisterFlags(flags *flag.FlagSet) {
 	v.fs = flags
+
+	flags.StringVar(&v.vendorDir, "vendor-dir", "", "The directory that packages are installed in")
 }
 
 func (v *VerifyCommand) Run() error {
 
+	root, err := common.LoadPackage("")
+	if err != nil {
+		v.Error(err)
+		return err
+	}
+
 	var path string
 	if v.fs.NArg() > 0 {
-		packageName := v.fs.Arg(0)
-		path = filepath.Join(core.Vendor, strings.Replace(packageName, ".", string(filepath.Separator), -1))
+		path = core.PackageDir(v.resolveVendorDir(v.vendorDir, root), v.fs.Arg(0))
 	} else {
 		path = "."
 	}
 
-	var err error
-	v.pkg, err = common.LoadPackage("")
+	// The fingerprint is the one in the package being verified
+	v.pkg, err = common.LoadPackage(path)
 	if err != nil {
 		v.Error(err)
 		return err
@@ -60,7 +69,7 @@ func (v *VerifyCommand) Run() error {
 
 	// Hash the package
 
-	hash, err := v.hashTree(path)
+	hash, err := common.HashTree(path)
 	if err != nil {
 		v.Error(err)
 		return err
@@ -69,57 +78,128 @@ func (v *VerifyCommand) Run() error {
 
 	// Verify the signature
 
-	if v.pkg.Version.Fingerprint == "" {
-		err = fmt.Errorf("no fingerprint set in " + core.PackageFile)
+	verifier, err := newSignatureVerifier(v.BaseCommand, v.Ctx.Client)
+	if err != nil {
 		v.Error(err)
 		return err
 	}
 
-	entity, err := entityFromLocal("pubring.gpg", v.pkg.Version.Fingerprint)
-	if err != nil {
+	if err = verifier.verify(v.pkg, hash); err != nil {
 		v.Error(err)
 		return err
 	}
 
-	sig, err := ioutil.ReadFile(core.SignatureFile)
+	fmt.Println("Signature verified")
+
+	return nil
+}
+
+// signatureVerifier checks package signatures against the keyring. A package that is not
+// signed with a trusted key is trusted on first use: the key is fetched from the registry if
+// needed and the package is pinned to it, so that a later release signed with a different
+// key is refused. It is safe to use from multiple goroutines.
+type signatureVerifier struct {
+	BaseCommand
+	// client fetches unknown keys. If it is nil, only keys in the keyring are used.
+	client  msg.QpmClient
+	keyring *common.Keyring
+	mutex   sync.Mutex
+}
+
+func newSignatureVerifier(base BaseCommand, client msg.QpmClient) (*signatureVerifier, error) {
+	keyring, err := common.LoadKeyring()
 	if err != nil {
-		v.Error(err)
-		return err
+		return nil, err
 	}
+	return &signatureVerifier{
+		BaseCommand: base,
+		client:      client,
+		keyring:     keyring,
+	}, nil
+}
 
-	err = Verify(hash, sig, entity.PrimaryKey)
-	if err != nil {
-		v.Error(err)
+// verify checks that the package's signature file is a signature of hash made with the key
+// whose fingerprint is in its package file, and that the key is trusted for the package.
+func (sv *signatureVerifier) verify(pkg *common.PackageWrapper, hash string) error {
+
+	if pkg.Version == nil || pkg.Version.Fingerprint == "" {
+		return fmt.Errorf("%s is not signed, there is no fingerprint in %s", pkg.Name, core.PackageFile)
+	}
+	fingerprint := common.NormalizeFingerprint(pkg.Version.Fingerprint)
+
+	sig, err := ioutil.ReadFile(filepath.Join(pkg.RootDir(), core.SignatureFile))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s is not signed, there is no %s", pkg.Name, core.SignatureFile)
+	} else if err != nil {
 		return err
 	}
 
-	fmt.Println("Signature verified")
+	sv.mutex.Lock()
+	defer sv.mutex.Unlock()
 
-	return nil
-}
+	if sv.keyring.IsRevoked(fingerprint) {
+		return fmt.Errorf("%s is signed with %s which has been revoked", pkg.Name, fingerprint)
+	}
 
-func (v *VerifyCommand) visit(path string, f os.FileInfo, err error) error {
+	key := sv.keyring.Find(fingerprint)
+	trusted := key != nil && sv.keyring.Trusts(key, pkg.Name)
 
-	if f.IsDir() {
-		if strings.HasPrefix(f.Name(), ".git") {
-			return filepath.SkipDir
+	if !trusted {
+		if pinned := sv.keyring.Pinned(pkg.Name); pinned != "" && pinned != fingerprint {
+			return fmt.Errorf("%s is signed with %s but was signed with %s before. "+
+				"If the publisher has changed keys, use 'qpm keys trust' to trust the new one",
+				pkg.Name, fingerprint, pinned)
 		}
-	} else {
-		v.paths = append(v.paths, path)
+		if key == nil {
+			if key, err = sv.fetch(pkg.Name, fingerprint); err != nil {
+				return err
+			}
+		}
+	}
+
+	entity, err := key.Entity()
+	if err != nil {
+		return err
 	}
+
+	if err = Verify(hash, sig, entity.PrimaryKey); err != nil {
+		return fmt.Errorf("%s has a bad signature: %v", pkg.Name, err)
+	}
+
+	if !trusted {
+		sv.Info(fmt.Sprintf("Trusting %s %s for %s from now on", key.Fingerprint, key.Identity, pkg.Name))
+		sv.keyring.Pin(pkg.Name, key.Fingerprint)
+		if err = sv.keyring.Save(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (v *VerifyCommand) hashTree(directory string) (string, error) {
-
-	v.paths = []string{}
+// fetch gets the key with the given fingerprint from the registry that serves the package
+// and adds it to the keyring. Revoked keys are not fetched.
+func (sv *signatureVerifier) fetch(name string, fingerprint string) (*common.TrustedKey, error) {
+	if sv.keyring.IsRevoked(fingerprint) {
+		return nil, fmt.Errorf("%s is signed with %s which has been revoked", name, fingerprint)
+	}
+	if sv.client == nil {
+		return nil, fmt.Errorf("%s is signed with %s which is not in the keyring", name, fingerprint)
+	}
 
-	err := filepath.Walk(directory, v.visit)
+	response, err := sv.client.GetKey(context.Background(), &msg.KeyRequest{
+		Fingerprint: fingerprint,
+		PackageName: name,
+	})
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%s is signed with %s which could not be fetched from the registry: %v", name, fingerprint, err)
 	}
 
-	return HashPaths(v.paths)
+	key, err := sv.keyring.ImportKey(response.PublicKey, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("the registry sent a bad key for %s: %v", name, err)
+	}
+	return key, nil
 }
 
 func Verify(payload string, signature []byte, pubkey *packet.PublicKey) error {
@@ -141,7 +221,7 @@ func Verify(payload string, signature []byte, pubkey *packet.PublicKey) error {
 		return fmt.Errorf("could not parse the signature")
 	}
 
-	if sig.Hash != crypto.SHA256 || sig.Hash != crypto.SHA512 {
+	if sig.Hash != crypto.SHA256 && sig.Hash != crypto.SHA512 {
 		return fmt.Errorf("was not a SHA-256 or SHA-512 signature")
 	}
 
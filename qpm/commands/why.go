@@ -0,0 +1,108 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"qpm.io/common"
+	"qpm.io/qpm/core"
+)
+
+type WhyCommand struct {
+	BaseCommand
+	fs        *flag.FlagSet
+	vendorDir string
+}
+
+func NewWhyCommand(ctx core.Context) *WhyCommand {
+	return &WhyCommand{
+		BaseCommand: BaseCommand{
+			Ctx: ctx,
+		},
+	}
+}
+
+func (w WhyCommand) Description() string {
+	return "Explains why a package is installed"
+}
+
+func (w *WhyCommand) RegisterFlags(flags *flag.FlagSet) {
+	w.fs = flags
+
+	flags.StringVar(&w.vendorDir, "vendor-dir", "", "The directory that packages are installed in")
+}
+
+func (w *WhyCommand) Run() error {
+
+	packageName := strings.ToLower(w.fs.Arg(0))
+
+	if packageName == "" {
+		err := fmt.Errorf("Must supply a package name")
+		w.Error(err)
+		return err
+	}
+
+	pkg, err := common.LoadPackage("")
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("No %s file found", core.PackageFile)
+		}
+		w.Error(err)
+		return err
+	}
+
+	w.vendorDir = w.resolveVendorDir(w.vendorDir, pkg)
+
+	graph, err := common.LoadDependencyGraph(pkg, w.vendorDir)
+	if err != nil {
+		w.Error(err)
+		return err
+	}
+
+	target, installed := graph.Packages[packageName]
+	paths := graph.Paths(packageName)
+
+	if len(paths) == 0 {
+		if installed {
+			err = fmt.Errorf("%s is installed but nothing depends on it", installedLabel(target))
+		} else {
+			err = fmt.Errorf("Package %s was not found", packageName)
+		}
+		w.Error(err)
+		return err
+	}
+
+	if installed {
+		fmt.Printf("%s is required by:\n", installedLabel(target))
+	} else {
+		fmt.Printf("%s (missing) is required by:\n", packageName)
+	}
+
+	for _, path := range paths {
+		steps := []string{graph.RootName()}
+		for _, e := range path {
+			step := e.Name
+			if e.Constraint != "" {
+				step += "@" + e.Constraint
+			}
+			steps = append(steps, step)
+		}
+		fmt.Println("\t" + strings.Join(steps, " -> "))
+	}
+
+	return nil
+}
+
+// installedLabel returns the name and version of an installed package. Package files that
+// have no version are labelled as in qpm tree.
+func installedLabel(pw *common.PackageWrapper) string {
+	if label := pw.VersionLabel(); label != "" {
+		return pw.Name + "@" + label
+	}
+	return pw.Name + " (unknown version)"
+}
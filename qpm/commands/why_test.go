@@ -0,0 +1,46 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+	qpmtesting "qpm.io/qpm/testing"
+)
+
+func TestWhyUnversionedPackage(t *testing.T) {
+	f, remove := newFixture(t)
+	defer remove()
+
+	client := qpmtesting.NewFakeClient()
+	project, err := f.Project("app", "io.qpm.alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chdir(t, project)()
+
+	// Vendored package files do not have to have a version
+	for _, name := range []string{"io.qpm.alpha", "io.qpm.beta"} {
+		dir := core.PackageDir(core.Vendor, name)
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = qpmtesting.WritePackage(dir, &msg.Package{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = run(t, NewWhyCommand(client.Context()), "io.qpm.alpha"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = run(t, NewWhyCommand(client.Context()), "io.qpm.beta")
+	if err == nil || !strings.Contains(err.Error(), "io.qpm.beta (unknown version)") {
+		t.Errorf("expected io.qpm.beta to be reported as unused with an unknown version, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package core
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	msg "qpm.io/common/messages"
+)
+
+// lazyClient only connects to the server the first time a request is made, so commands
+// that work offline never need the network.
+type lazyClient struct {
+	dial   func() (*grpc.ClientConn, error)
+	once   sync.Once
+	client msg.QpmClient
+	err    error
+}
+
+func newLazyClient(dial func() (*grpc.ClientConn, error)) *lazyClient {
+	return &lazyClient{
+		dial: dial,
+	}
+}
+
+func (c *lazyClient) connect() (msg.QpmClient, error) {
+	c.once.Do(func() {
+		conn, err := c.dial()
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.client = msg.NewQpmClient(conn)
+	})
+	return c.client, c.err
+}
+
+func (c *lazyClient) Ping(ctx context.Context, in *msg.PingRequest, opts ...grpc.CallOption) (*msg.PingResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.Ping(ctx, in, opts...)
+}
+
+func (c *lazyClient) Publish(ctx context.Context, in *msg.PublishRequest, opts ...grpc.CallOption) (*msg.PublishResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.Publish(ctx, in, opts...)
+}
+
+func (c *lazyClient) GetDependencies(ctx context.Context, in *msg.DependencyRequest, opts ...grpc.CallOption) (*msg.DependencyResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetDependencies(ctx, in, opts...)
+}
+
+func (c *lazyClient) Search(ctx context.Context, in *msg.SearchRequest, opts ...grpc.CallOption) (*msg.SearchResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.Search(ctx, in, opts...)
+}
+
+func (c *lazyClient) List(ctx context.Context, in *msg.ListRequest, opts ...grpc.CallOption) (*msg.ListResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.List(ctx, in, opts...)
+}
+
+func (c *lazyClient) Login(ctx context.Context, in *msg.LoginRequest, opts ...grpc.CallOption) (*msg.LoginResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.Login(ctx, in, opts...)
+}
+
+func (c *lazyClient) Info(ctx context.Context, in *msg.InfoRequest, opts ...grpc.CallOption) (*msg.InfoResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.Info(ctx, in, opts...)
+}
+
+func (c *lazyClient) GetLicense(ctx context.Context, in *msg.LicenseRequest, opts ...grpc.CallOption) (*msg.LicenseResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetLicense(ctx, in, opts...)
+}
+
+func (c *lazyClient) PublishKey(ctx context.Context, in *msg.PublishKeyRequest, opts ...grpc.CallOption) (*msg.PublishKeyResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.PublishKey(ctx, in, opts...)
+}
+
+func (c *lazyClient) GetKey(ctx context.Context, in *msg.KeyRequest, opts ...grpc.CallOption) (*msg.KeyResponse, error) {
+	client, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetKey(ctx, in, opts...)
+}
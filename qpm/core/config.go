@@ -0,0 +1,240 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ConfigFile is the name of the user and project configuration files.
+const ConfigFile = ".qpmrc"
+
+// Config holds the settings read from the configuration files. Empty fields are not set.
+type Config struct {
+	// Registry is the address of the default registry.
+	Registry string `json:"registry,omitempty"`
+	// CAFile is a PEM file with the certificates used to verify the default registry.
+	CAFile    string `json:"caFile,omitempty"`
+	VendorDir string `json:"vendorDir,omitempty"`
+	// License is the license suggested by qpm init.
+	License string `json:"license,omitempty"`
+	Author  struct {
+		Name  string `json:"name,omitempty"`
+		Email string `json:"email,omitempty"`
+	} `json:"author"`
+	// SigningKey is the fingerprint of the PGP key used by qpm sign when the package file
+	// does not have one.
+	SigningKey string `json:"signingKey,omitempty"`
+	// SignaturePolicy decides what qpm install does with packages that are not signed by a
+	// trusted key. It is one of SignaturesOff, SignaturesWarn or SignaturesRequire.
+	SignaturePolicy string `json:"signaturePolicy,omitempty"`
+	// Proxy is used for HTTP and HTTPS connections unless HTTP_PROXY or HTTPS_PROXY is set.
+	Proxy    string `json:"proxy,omitempty"`
+	CacheDir string `json:"cacheDir,omitempty"`
+}
+
+// ConfigKey is a setting that can be read and changed with qpm config.
+type ConfigKey struct {
+	Name        string
+	Description string
+	field       func(c *Config) *string
+}
+
+// ConfigKeys lists every setting in the configuration files.
+var ConfigKeys = []ConfigKey{
+	{"registry", "Address of the default registry", func(c *Config) *string { return &c.Registry }},
+	{"caFile", "PEM file used to verify the default registry", func(c *Config) *string { return &c.CAFile }},
+	{"vendorDir", "Directory that packages are installed in", func(c *Config) *string { return &c.VendorDir }},
+	{"license", "License suggested by qpm init", func(c *Config) *string { return &c.License }},
+	{"author.name", "Author name suggested by qpm init", func(c *Config) *string { return &c.Author.Name }},
+	{"author.email", "Author email suggested by qpm init", func(c *Config) *string { return &c.Author.Email }},
+	{"signingKey", "Fingerprint of the PGP key used by qpm sign", func(c *Config) *string { return &c.SigningKey }},
+	{"signaturePolicy", "What install does with unsigned packages: off, warn or require", func(c *Config) *string { return &c.SignaturePolicy }},
+	{"proxy", "Proxy used for HTTP and HTTPS connections", func(c *Config) *string { return &c.Proxy }},
+	{"cacheDir", "Directory of the local package cache", func(c *Config) *string { return &c.CacheDir }},
+}
+
+// userOnlyKeys are the settings that decide which servers qpm talks to and trusts, and
+// where it keeps files outside of the project. The project file comes with the code that is
+// being built, so it cannot change them.
+var userOnlyKeys = map[string]bool{
+	"registry": true,
+	"caFile":   true,
+	"proxy":    true,
+	"cacheDir": true,
+}
+
+func configKey(name string) (ConfigKey, error) {
+	for _, key := range ConfigKeys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return ConfigKey{}, fmt.Errorf("Unknown configuration key %q", name)
+}
+
+// Get returns the value of the setting with the given name.
+func (c *Config) Get(name string) (string, error) {
+	key, err := configKey(name)
+	if err != nil {
+		return "", err
+	}
+	return *key.field(c), nil
+}
+
+// Set changes the setting with the given name. An empty value unsets it.
+func (c *Config) Set(name string, value string) error {
+	key, err := configKey(name)
+	if err != nil {
+		return err
+	}
+	*key.field(c) = value
+	return nil
+}
+
+// merge overrides the settings in c with the ones that are set in other.
+func (c *Config) merge(other *Config) {
+	for _, key := range ConfigKeys {
+		if value := *key.field(other); value != "" {
+			*key.field(c) = value
+		}
+	}
+}
+
+// CheckProjectSetting returns an error if the project file may not set the setting with the
+// given name to value on top of the settings in c. The project file can make signature
+// checks stricter but not weaker, can only put the vendor directory inside the project, and
+// cannot set the keys in userOnlyKeys at all.
+func (c *Config) CheckProjectSetting(name string, value string) error {
+	if value == "" {
+		return nil
+	}
+	if userOnlyKeys[name] {
+		return fmt.Errorf("%s can only be set in the user or system configuration", name)
+	}
+	if name == "vendorDir" && !insideProject(value) {
+		return fmt.Errorf("the project configuration can only set vendorDir to a directory inside the project")
+	}
+	if name == "signaturePolicy" && policyLevel(value) < policyLevel(c.SignaturePolicy) {
+		return fmt.Errorf("the project configuration cannot lower the signature policy to %s", value)
+	}
+	return nil
+}
+
+// insideProject reports whether the path is relative and does not leave the directory it is
+// relative to, since install and update remove packages in the vendor directory.
+func insideProject(path string) bool {
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// policyLevel orders the signature policies from the weakest to the strongest. Unknown
+// policies are reported by Context.SignaturePolicy, so they count as the default here.
+func policyLevel(policy string) int {
+	switch policy {
+	case SignaturesOff:
+		return 0
+	case SignaturesRequire:
+		return 2
+	}
+	return 1
+}
+
+// mergeProject is merge for the project file. Settings that CheckProjectSetting rejects are
+// left out and returned as warnings.
+func (c *Config) mergeProject(project *Config) []string {
+	var warnings []string
+	for _, key := range ConfigKeys {
+		value := *key.field(project)
+		if err := c.CheckProjectSetting(key.Name, value); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Ignoring %s in %s: %v", key.Name, ProjectConfigPath(), err))
+		} else if value != "" {
+			*key.field(c) = value
+		}
+	}
+	return warnings
+}
+
+// Save writes the configuration to the given file.
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadConfig reads a single configuration file. A missing file is an empty configuration.
+func ReadConfig(path string) (*Config, error) {
+	config := &Config{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+// SystemConfigPath returns the path of the configuration file shared by every user.
+func SystemConfigPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "qpm", "qpmrc")
+	}
+	return "/etc/qpmrc"
+}
+
+// UserConfigPath returns the path of the current user's configuration file.
+func UserConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(ConfigDir(), ConfigFile)
+	}
+	return filepath.Join(home, ConfigFile)
+}
+
+// ProjectConfigPath returns the path of the configuration file for the project in the
+// current directory.
+func ProjectConfigPath() string {
+	return ConfigFile
+}
+
+// LoadConfig reads the system, user and project configuration files. Settings in the
+// project file override the ones in the user file, which override the system file, except
+// for the ones the project file may not change. Those are ignored and returned as warnings.
+func LoadConfig() (*Config, []string, error) {
+	config := &Config{}
+	for _, path := range []string{SystemConfigPath(), UserConfigPath()} {
+		layer, err := ReadConfig(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		config.merge(layer)
+	}
+
+	project, err := ReadConfig(ProjectConfigPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, config.mergeProject(project), nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// configFiles writes the user and project configuration files into a new directory and
+// changes to the project. The returned function restores the environment and removes it.
+func configFiles(t *testing.T, user string, project string) func() {
+	dir, err := ioutil.TempDir("", "qpm-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectDir := filepath.Join(dir, "project")
+	if err = os.Mkdir(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, ConfigFile), []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(projectDir, ConfigFile), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	home := os.Getenv("HOME")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("HOME", dir)
+	if err = os.Chdir(projectDir); err != nil {
+		t.Fatal(err)
+	}
+
+	return func() {
+		os.Chdir(wd)
+		os.Setenv("HOME", home)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLoadConfigIgnoresUnsafeProjectSettings(t *testing.T) {
+	defer configFiles(t,
+		`{"registry": "qpm.example.com:7000", "signaturePolicy": "require"}`,
+		`{"registry": "evil.example.com:7000", "caFile": "evil.pem", "proxy": "http://evil.example.com",
+		  "signaturePolicy": "off", "cacheDir": "/", "vendorDir": "3rdparty"}`,
+	)()
+
+	config, warnings, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"registry":        "qpm.example.com:7000",
+		"caFile":          "",
+		"proxy":           "",
+		"signaturePolicy": SignaturesRequire,
+		"cacheDir":        "",
+		"vendorDir":       "3rdparty",
+	}
+	for name, value := range expected {
+		if actual, _ := config.Get(name); actual != value {
+			t.Errorf("expected %s to be %q, got %q", name, value, actual)
+		}
+	}
+	if len(warnings) != 5 {
+		t.Errorf("expected 5 warnings, got %q", warnings)
+	}
+}
+
+func TestLoadConfigKeepsVendorDirInsideProject(t *testing.T) {
+	for _, vendorDir := range []string{"/tmp", "..", "vendor/../..", "3rdparty/../../home"} {
+		restore := configFiles(t, `{}`, `{"vendorDir": "`+vendorDir+`"}`)
+
+		config, warnings, err := LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.VendorDir != "" || len(warnings) != 1 {
+			t.Errorf("expected vendorDir %q to be ignored, got %q and warnings %q", vendorDir, config.VendorDir, warnings)
+		}
+		restore()
+	}
+}
+
+func TestLoadConfigLetsProjectRaiseSignaturePolicy(t *testing.T) {
+	defer configFiles(t, `{"signaturePolicy": "off"}`, `{"signaturePolicy": "require"}`)()
+
+	config, warnings, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.SignaturePolicy != SignaturesRequire {
+		t.Errorf("expected the project to require signatures, got %q", config.SignaturePolicy)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %q", warnings)
+	}
+}
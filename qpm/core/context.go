@@ -2,10 +2,9 @@ package core
 
 import (
 	"fmt"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 	"log"
 	"os"
+	"path/filepath"
 	msg "qpm.io/common/messages"
 	"runtime"
 )
@@ -17,44 +16,112 @@ var (
 
 const (
 	PackageFile   = "qpm.json"
+	LockFile      = "qpm.lock"
 	SignatureFile = "qpm.asc"
 	Vendor        = "vendor"
 	Address       = "pkg.qpm.io:7000"
 	LicenseFile   = "LICENSE"
 )
 
+// Signature policies for packages that are not signed by a trusted key
+const (
+	SignaturesOff     = "off"
+	SignaturesWarn    = "warn"
+	SignaturesRequire = "require"
+)
+
 var UA = fmt.Sprintf("qpm/%v (%s; %s)", Version, runtime.GOOS, runtime.GOARCH)
 
+// CacheDir returns the directory where packages are cached so that they can be shared
+// between projects. This is $XDG_CACHE_HOME/qpm if set, otherwise the platform's user
+// cache directory.
+func CacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "qpm")
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "qpm")
+	}
+	return filepath.Join(os.TempDir(), "qpm-cache")
+}
+
+// ConfigDir returns the directory containing the user's qpm configuration. This is
+// $XDG_CONFIG_HOME/qpm if set, otherwise the platform's user configuration directory.
+func ConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "qpm")
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "qpm")
+	}
+	return filepath.Join(os.TempDir(), "qpm-config")
+}
+
 type Context struct {
-	Log    *log.Logger
-	Client msg.QpmClient
+	Log        *log.Logger
+	Client     msg.QpmClient
+	Registries *Registries
+	Config     *Config
 }
 
 func NewContext() *Context {
 	log := log.New(os.Stderr, "QPM: ", log.LstdFlags)
 
-	address := os.Getenv("SERVER")
-	if address == "" {
-		address = Address
+	config, warnings, err := LoadConfig()
+	if err != nil {
+		log.Fatalf("Could not load the configuration: %v", err)
 	}
-
-	noTls := os.Getenv("NO_TLS") == "1"
-
-	var tlsOption grpc.DialOption
-	if noTls {
-		tlsOption = grpc.WithInsecure()
-	} else {
-		tlsOption = grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, ""))
+	for _, warning := range warnings {
+		log.Println(warning)
 	}
 
-	conn, err := grpc.Dial(address, tlsOption, grpc.WithUserAgent(UA))
+	if config.Proxy != "" {
+		for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+			if os.Getenv(name) == "" {
+				os.Setenv(name, config.Proxy)
+			}
+		}
+	}
 
+	registries, err := LoadRegistries(config)
 	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+		log.Fatalf("Could not load the registries: %v", err)
 	}
 
 	return &Context{
-		Log:    log,
-		Client: msg.NewQpmClient(conn),
+		Log:        log,
+		Client:     NewRoutingClient(registries),
+		Registries: registries,
+		Config:     config,
+	}
+}
+
+// VendorDir returns the directory that packages are installed in.
+func (c Context) VendorDir() string {
+	if c.Config != nil && c.Config.VendorDir != "" {
+		return c.Config.VendorDir
+	}
+	return Vendor
+}
+
+// CacheDir returns the directory of the local package cache.
+func (c Context) CacheDir() string {
+	if c.Config != nil && c.Config.CacheDir != "" {
+		return c.Config.CacheDir
+	}
+	return CacheDir()
+}
+
+// SignaturePolicy returns what should happen when an installed package is not signed by a
+// trusted key. Packages are installed with a warning unless the configuration says otherwise.
+func (c Context) SignaturePolicy() (string, error) {
+	if c.Config == nil || c.Config.SignaturePolicy == "" {
+		return SignaturesWarn, nil
+	}
+	switch c.Config.SignaturePolicy {
+	case SignaturesOff, SignaturesWarn, SignaturesRequire:
+		return c.Config.SignaturePolicy, nil
 	}
+	return "", fmt.Errorf("Unknown signature policy %q, expected %s, %s or %s",
+		c.Config.SignaturePolicy, SignaturesOff, SignaturesWarn, SignaturesRequire)
 }
@@ -0,0 +1,93 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// CredentialsFile is the name of the file in ConfigDir that stores the tokens from qpm login.
+const CredentialsFile = "credentials.json"
+
+// Credentials maps registry addresses to the tokens used to publish packages to them.
+type Credentials struct {
+	path   string
+	Tokens map[string]string `json:"tokens"`
+}
+
+// CredentialsPath returns the path of the credentials file.
+func CredentialsPath() string {
+	return filepath.Join(ConfigDir(), CredentialsFile)
+}
+
+// LoadCredentials reads the credentials file. A missing file has no tokens.
+func LoadCredentials() (*Credentials, error) {
+	credentials := &Credentials{
+		path:   CredentialsPath(),
+		Tokens: make(map[string]string),
+	}
+
+	data, err := ioutil.ReadFile(credentials.path)
+	if os.IsNotExist(err) {
+		return credentials, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, credentials); err != nil {
+		return nil, fmt.Errorf("%s: %v", credentials.path, err)
+	}
+	if credentials.Tokens == nil {
+		credentials.Tokens = make(map[string]string)
+	}
+	return credentials, nil
+}
+
+// Token returns the token for the registry with the given address, or an empty string.
+func (c *Credentials) Token(address string) string {
+	return c.Tokens[address]
+}
+
+// SetToken stores the token for the registry with the given address. An empty token
+// removes it.
+func (c *Credentials) SetToken(address string, token string) {
+	if token == "" {
+		delete(c.Tokens, address)
+	} else {
+		c.Tokens[address] = token
+	}
+}
+
+// Save writes the credentials file so that only the current user can read it.
+func (c *Credentials) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	// Write a new file, which TempFile creates with mode 0600, instead of rewriting an
+	// existing one that may be readable by others
+	file, err := ioutil.TempFile(filepath.Dir(c.path), ".credentials-")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	msg "qpm.io/common/messages"
+)
+
+// Generator writes the files that a build system needs in order to use the packages
+// installed in a vendor directory. Packages are described by their messages rather than
+// common.PackageWrapper since qpm.io/common depends on this package.
+type Generator interface {
+	// Generate writes the files for the root package and its installed dependencies. The
+	// dependencies are given in the order they should be included in the build.
+	Generate(vendorDir string, root *msg.Package, dependencies []*msg.Package) error
+}
+
+// DefaultGenerators are run when the package file does not list any build systems.
+var DefaultGenerators = []string{"qmake", "cmake"}
+
+var (
+	generatorsMutex sync.RWMutex
+	generators      = make(map[string]Generator)
+)
+
+// RegisterGenerator makes a generator available under the given build system name. It
+// panics if the name is already taken.
+func RegisterGenerator(name string, generator Generator) {
+	generatorsMutex.Lock()
+	defer generatorsMutex.Unlock()
+
+	if _, exists := generators[name]; exists {
+		panic("generator already registered: " + name)
+	}
+	generators[name] = generator
+}
+
+// LookupGenerator returns the generator for the given build system.
+func LookupGenerator(name string) (Generator, error) {
+	generatorsMutex.RLock()
+	defer generatorsMutex.RUnlock()
+
+	generator, ok := generators[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown build system %q, expected one of %s", name, strings.Join(generatorNames(), ", "))
+	}
+	return generator, nil
+}
+
+// GeneratorNames returns the names of the registered build systems in sorted order.
+func GeneratorNames() []string {
+	generatorsMutex.RLock()
+	defer generatorsMutex.RUnlock()
+
+	return generatorNames()
+}
+
+func generatorNames() []string {
+	var names []string
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PackageDir returns the directory in vendorDir that the package with the given name is
+// installed in.
+func PackageDir(vendorDir string, name string) string {
+	return filepath.Join(vendorDir, strings.Replace(name, ".", string(filepath.Separator), -1))
+}
@@ -0,0 +1,172 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	msg "qpm.io/common/messages"
+)
+
+// RegistriesFile is the name of the file in ConfigDir that lists the registries.
+const RegistriesFile = "registries.json"
+
+// Registry is a package registry and the packages that should be fetched from it.
+type Registry struct {
+	// Prefix selects the packages served by this registry. The prefix "com.example" matches
+	// com.example and com.example.*. The registry with an empty prefix serves all of the
+	// packages that no other registry matches.
+	Prefix  string `json:"prefix"`
+	Address string `json:"address"`
+	// NoTLS disables TLS, like NO_TLS=1 does for the default registry.
+	NoTLS bool `json:"noTls,omitempty"`
+	// CAFile is a PEM file with the certificates used to verify the server instead of the
+	// system certificates.
+	CAFile string `json:"caFile,omitempty"`
+	// Token is used to publish packages instead of logging in.
+	Token string `json:"token,omitempty"`
+
+	once   sync.Once
+	client msg.QpmClient
+}
+
+// Matches reports whether the package with the given name is served by the registry.
+func (r *Registry) Matches(name string) bool {
+	return r.Prefix == "" || name == r.Prefix || strings.HasPrefix(name, r.Prefix+".")
+}
+
+// Client returns a client for the registry. It does not connect until it is used.
+func (r *Registry) Client() msg.QpmClient {
+	r.once.Do(func() {
+		r.client = newLazyClient(r.dial)
+	})
+	return r.client
+}
+
+func (r *Registry) dial() (*grpc.ClientConn, error) {
+	var tlsOption grpc.DialOption
+	if r.NoTLS {
+		tlsOption = grpc.WithInsecure()
+	} else if r.CAFile != "" {
+		creds, err := credentials.NewClientTLSFromFile(r.CAFile, "")
+		if err != nil {
+			return nil, err
+		}
+		tlsOption = grpc.WithTransportCredentials(creds)
+	} else {
+		tlsOption = grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, ""))
+	}
+	return grpc.Dial(r.Address, tlsOption, grpc.WithUserAgent(UA))
+}
+
+// Registries is the set of registries that packages are fetched from.
+type Registries struct {
+	// sorted by descending prefix length so that the most specific registry comes first
+	list []*Registry
+}
+
+// NewRegistries returns a set of registries that falls back to defaultRegistry for packages
+// that none of the others match. A registry in the list with an empty prefix replaces
+// defaultRegistry.
+func NewRegistries(defaultRegistry *Registry, registries ...*Registry) *Registries {
+	list := []*Registry{}
+	hasDefault := false
+	for _, r := range registries {
+		list = append(list, r)
+		hasDefault = hasDefault || r.Prefix == ""
+	}
+	if !hasDefault {
+		list = append(list, defaultRegistry)
+	}
+
+	sort.Stable(byPrefixLength(list))
+
+	return &Registries{
+		list: list,
+	}
+}
+
+// LoadRegistries reads the registries from RegistriesFile in ConfigDir. The default registry
+// is given by the SERVER and NO_TLS environment variables, the configuration, or Address.
+func LoadRegistries(config *Config) (*Registries, error) {
+	defaultRegistry := &Registry{
+		Address: os.Getenv("SERVER"),
+		NoTLS:   os.Getenv("NO_TLS") == "1",
+		CAFile:  config.CAFile,
+	}
+	if defaultRegistry.Address == "" {
+		defaultRegistry.Address = config.Registry
+	}
+	if defaultRegistry.Address == "" {
+		defaultRegistry.Address = Address
+	}
+
+	var file struct {
+		Registries []*Registry `json:"registries"`
+	}
+
+	path := filepath.Join(ConfigDir(), RegistriesFile)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewRegistries(defaultRegistry), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for _, r := range file.Registries {
+		if r.Address == "" {
+			return nil, fmt.Errorf("%s: the registry for %q has no address", path, r.Prefix)
+		}
+	}
+
+	return NewRegistries(defaultRegistry, file.Registries...), nil
+}
+
+// For returns the registry that serves the package with the given name.
+func (r *Registries) For(name string) *Registry {
+	for _, registry := range r.list {
+		if registry.Matches(name) {
+			return registry
+		}
+	}
+	return r.Default()
+}
+
+// ByAddress returns the registry with the given address, or nil if there is none.
+func (r *Registries) ByAddress(address string) *Registry {
+	for _, registry := range r.list {
+		if registry.Address == address {
+			return registry
+		}
+	}
+	return nil
+}
+
+// Default returns the registry used for requests that are not about a specific package.
+func (r *Registries) Default() *Registry {
+	return r.list[len(r.list)-1]
+}
+
+// All returns every registry, most specific prefix first.
+func (r *Registries) All() []*Registry {
+	return append([]*Registry{}, r.list...)
+}
+
+type byPrefixLength []*Registry
+
+func (b byPrefixLength) Len() int           { return len(b) }
+func (b byPrefixLength) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPrefixLength) Less(i, j int) bool { return len(b[i].Prefix) > len(b[j].Prefix) }
@@ -0,0 +1,194 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	msg "qpm.io/common/messages"
+)
+
+// routingClient sends each request to the registry that serves the package it is about.
+// Requests that are not about a single package go to every registry, or the default one.
+type routingClient struct {
+	registries *Registries
+}
+
+// NewRoutingClient returns a client that uses the given registries.
+func NewRoutingClient(registries *Registries) msg.QpmClient {
+	return &routingClient{
+		registries: registries,
+	}
+}
+
+// Ping checks that every registry can be reached.
+func (c *routingClient) Ping(ctx context.Context, in *msg.PingRequest, opts ...grpc.CallOption) (*msg.PingResponse, error) {
+	for _, r := range c.registries.All() {
+		if _, err := r.Client().Ping(ctx, in, opts...); err != nil {
+			return nil, registryError(r, err)
+		}
+	}
+	return &msg.PingResponse{}, nil
+}
+
+// Publish sends the package to its registry, using the registry's token if the request
+// does not have one.
+func (c *routingClient) Publish(ctx context.Context, in *msg.PublishRequest, opts ...grpc.CallOption) (*msg.PublishResponse, error) {
+	var name string
+	if in.PackageDescription != nil {
+		name = in.PackageDescription.Name
+	}
+	r := c.registries.For(name)
+	if in.Token == "" && r.Token != "" {
+		request := *in
+		request.Token = r.Token
+		in = &request
+	}
+	return r.Client().Publish(ctx, in, opts...)
+}
+
+// GetDependencies asks each registry about its own packages and merges the answers. Since
+// a registry does not know about the packages in the others, errors from individual
+// registries are reported as warnings when more than one registry is involved.
+func (c *routingClient) GetDependencies(ctx context.Context, in *msg.DependencyRequest, opts ...grpc.CallOption) (*msg.DependencyResponse, error) {
+	var order []*Registry
+	names := make(map[*Registry][]string)
+	for _, p := range in.PackageNames {
+		r := c.registries.For(packageName(p))
+		if _, ok := names[r]; !ok {
+			order = append(order, r)
+		}
+		names[r] = append(names[r], p)
+	}
+
+	if len(order) == 1 {
+		return order[0].Client().GetDependencies(ctx, in, opts...)
+	}
+
+	response := &msg.DependencyResponse{}
+	seen := make(map[string]bool)
+	for _, r := range order {
+		request := *in
+		request.PackageNames = names[r]
+
+		partial, err := r.Client().GetDependencies(ctx, &request, opts...)
+		if err != nil {
+			response.Messages = append(response.Messages, &msg.DependencyMessage{
+				Type:  msg.MessageType_WARNING,
+				Title: registryError(r, err).Error(),
+			})
+			continue
+		}
+
+		for _, d := range partial.Dependencies {
+			if !seen[d.Name] {
+				seen[d.Name] = true
+				response.Dependencies = append(response.Dependencies, d)
+			}
+		}
+		response.Messages = append(response.Messages, partial.Messages...)
+	}
+
+	return response, nil
+}
+
+func (c *routingClient) Search(ctx context.Context, in *msg.SearchRequest, opts ...grpc.CallOption) (*msg.SearchResponse, error) {
+	results, err := c.merge(func(client msg.QpmClient) ([]*msg.SearchResult, error) {
+		response, err := client.Search(ctx, in, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return response.Results, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &msg.SearchResponse{Results: results}, nil
+}
+
+func (c *routingClient) List(ctx context.Context, in *msg.ListRequest, opts ...grpc.CallOption) (*msg.ListResponse, error) {
+	results, err := c.merge(func(client msg.QpmClient) ([]*msg.SearchResult, error) {
+		response, err := client.List(ctx, in, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return response.Results, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &msg.ListResponse{Results: results}, nil
+}
+
+// Login logs in to the default registry.
+func (c *routingClient) Login(ctx context.Context, in *msg.LoginRequest, opts ...grpc.CallOption) (*msg.LoginResponse, error) {
+	return c.registries.Default().Client().Login(ctx, in, opts...)
+}
+
+func (c *routingClient) Info(ctx context.Context, in *msg.InfoRequest, opts ...grpc.CallOption) (*msg.InfoResponse, error) {
+	return c.registries.For(in.PackageName).Client().Info(ctx, in, opts...)
+}
+
+// GetLicense gets license texts from the default registry.
+func (c *routingClient) GetLicense(ctx context.Context, in *msg.LicenseRequest, opts ...grpc.CallOption) (*msg.LicenseResponse, error) {
+	return c.registries.Default().Client().GetLicense(ctx, in, opts...)
+}
+
+// PublishKey uploads the key to the default registry, using the registry's token if the
+// request does not have one.
+func (c *routingClient) PublishKey(ctx context.Context, in *msg.PublishKeyRequest, opts ...grpc.CallOption) (*msg.PublishKeyResponse, error) {
+	r := c.registries.Default()
+	if in.Token == "" && r.Token != "" {
+		request := *in
+		request.Token = r.Token
+		in = &request
+	}
+	return r.Client().PublishKey(ctx, in, opts...)
+}
+
+// GetKey asks the registry that serves the package for the key that signs it.
+func (c *routingClient) GetKey(ctx context.Context, in *msg.KeyRequest, opts ...grpc.CallOption) (*msg.KeyResponse, error) {
+	return c.registries.For(in.PackageName).Client().GetKey(ctx, in, opts...)
+}
+
+// merge combines the results from every registry. A registry's results only include the
+// packages that would actually be installed from it.
+func (c *routingClient) merge(search func(client msg.QpmClient) ([]*msg.SearchResult, error)) ([]*msg.SearchResult, error) {
+	var results []*msg.SearchResult
+	for _, r := range c.registries.All() {
+		partial, err := search(r.Client())
+		if err != nil {
+			return nil, registryError(r, err)
+		}
+		for _, result := range partial {
+			if c.registries.For(result.Name) == r {
+				results = append(results, result)
+			}
+		}
+	}
+	sort.Sort(resultsByName(results))
+	return results, nil
+}
+
+func registryError(r *Registry, err error) error {
+	return fmt.Errorf("%s: %v", r.Address, err)
+}
+
+// packageName strips the version range from a dependency signature.
+func packageName(signature string) string {
+	if i := strings.Index(signature, "@"); i >= 0 {
+		return signature[:i]
+	}
+	return signature
+}
+
+type resultsByName []*msg.SearchResult
+
+func (r resultsByName) Len() int           { return len(r) }
+func (r resultsByName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r resultsByName) Less(i, j int) bool { return r[i].Name < r[j].Name }
@@ -39,10 +39,19 @@ func main() {
 	registry.RegisterSubCommand("install", cmd.NewInstallCommand(ctx))
 	registry.RegisterSubCommand("uninstall", cmd.NewUninstallCommand(ctx))
 	registry.RegisterSubCommand("publish", cmd.NewPublishCommand(ctx))
+	registry.RegisterSubCommand("login", cmd.NewLoginCommand(ctx))
+	registry.RegisterSubCommand("logout", cmd.NewLogoutCommand(ctx))
 	registry.RegisterSubCommand("help", cmd.NewHelpCommand(ctx))
 	registry.RegisterSubCommand("check", cmd.NewCheckCommand(ctx))
 	registry.RegisterSubCommand("sign", cmd.NewSignCommand(ctx))
 	registry.RegisterSubCommand("verify", cmd.NewVerifyCommand(ctx))
+	registry.RegisterSubCommand("keys", cmd.NewKeysCommand(ctx))
+	registry.RegisterSubCommand("tree", cmd.NewTreeCommand(ctx))
+	registry.RegisterSubCommand("why", cmd.NewWhyCommand(ctx))
+	registry.RegisterSubCommand("outdated", cmd.NewOutdatedCommand(ctx))
+	registry.RegisterSubCommand("update", cmd.NewUpdateCommand(ctx))
+	registry.RegisterSubCommand("cache", cmd.NewCacheCommand(ctx))
+	registry.RegisterSubCommand("config", cmd.NewConfigCommand(ctx))
 	//registry.RegisterSubCommand("deprecate", cmd.NewDeprecateCommand(ctx))
 	//registry.RegisterSubCommand("prune", cmd.NewPruneCommand(ctx))
 
@@ -0,0 +1,149 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package testing
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveEntry is an entry in a crafted tarball. Entries are written as given, so names
+// and link targets are not checked in any way.
+type ArchiveEntry struct {
+	Name     string
+	Type     byte
+	Content  string
+	Linkname string
+	// Size overrides the size in the header, which is otherwise the length of Content.
+	// Content is padded with zeros up to Size.
+	Size int64
+}
+
+// File, Dir, Symlink and Hardlink are shorthands for the common entry types.
+func File(name string, content string) ArchiveEntry {
+	return ArchiveEntry{Name: name, Type: tar.TypeReg, Content: content}
+}
+
+func Dir(name string) ArchiveEntry {
+	return ArchiveEntry{Name: name, Type: tar.TypeDir}
+}
+
+func Symlink(name string, target string) ArchiveEntry {
+	return ArchiveEntry{Name: name, Type: tar.TypeSymlink, Linkname: target}
+}
+
+func Hardlink(name string, target string) ArchiveEntry {
+	return ArchiveEntry{Name: name, Type: tar.TypeLink, Linkname: target}
+}
+
+// Archive writes a gzipped tarball with the given entries to name inside the fixture and
+// returns its path.
+func (f *Fixture) Archive(name string, entries ...ArchiveEntry) (string, error) {
+	path := filepath.Join(f.Dir, "archives", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.Name,
+			Typeflag: entry.Type,
+			Linkname: entry.Linkname,
+			Mode:     0644,
+		}
+		if entry.Type == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if entry.Type == tar.TypeReg {
+			header.Size = int64(len(entry.Content))
+			if entry.Size > header.Size {
+				header.Size = entry.Size
+			}
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return "", err
+		}
+		if header.Size > 0 {
+			content := entry.Content + strings.Repeat("\x00", int(header.Size)-len(entry.Content))
+			if _, err = tw.Write([]byte(content)); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return "", err
+	}
+	if err = gz.Close(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// UnsafeArchives writes a set of tarballs that an installer must refuse to extract and
+// returns their paths by the kind of attack. Each has a qpm.json in a top level directory
+// like the tarballs from GitHub. The tarball with an absolute path aims at escaped.txt in
+// the absolute directory outside.
+func (f *Fixture) UnsafeArchives(outside string) (map[string]string, error) {
+	pkg := []ArchiveEntry{
+		Dir("pkg/"),
+		File("pkg/qpm.json", `{"name": "io.qpm.unsafe"}`),
+	}
+
+	crafted := map[string][]ArchiveEntry{
+		"traversal":        {File("pkg/../../escaped.txt", "escaped")},
+		"absolute":         {File(filepath.ToSlash(filepath.Join(outside, "escaped.txt")), "escaped")},
+		"outside-top":      {File("other/escaped.txt", "escaped")},
+		"symlink-escape":   {Symlink("pkg/link", "../../.."), File("pkg/link/escaped.txt", "escaped")},
+		"symlink-absolute": {Symlink("pkg/link", "/etc/passwd")},
+		"hardlink-escape":  {Hardlink("pkg/link", "pkg/../../escaped.txt")},
+		"too-large":        {{Name: "pkg/large.bin", Type: tar.TypeReg, Size: 2 << 20}},
+		// Each link looks harmless on its own, but l2 is created through l and ends up
+		// pointing at the directory that pkg is in
+		"symlink-chain": {
+			Dir("pkg/sub/"),
+			Symlink("pkg/sub/l", ".."),
+			Symlink("pkg/sub/l/l2", ".."),
+		},
+		"symlink-through-link": {
+			Dir("pkg/sub/"),
+			Symlink("pkg/sub/l", ".."),
+			Symlink("pkg/l2", "sub/l/.."),
+		},
+		"hardlink-to-symlink": {
+			Dir("pkg/sub/"),
+			Symlink("pkg/sub/l", "../qpm.json"),
+			Hardlink("pkg/l", "pkg/sub/l"),
+		},
+	}
+
+	var many []ArchiveEntry
+	for i := 0; i < 100; i++ {
+		many = append(many, File(fmt.Sprintf("pkg/files/%d.txt", i), ""))
+	}
+	crafted["too-many-files"] = many
+
+	paths := make(map[string]string)
+	for kind, entries := range crafted {
+		path, err := f.Archive(kind+".tar.gz", append(append([]ArchiveEntry{}, pkg...), entries...)...)
+		if err != nil {
+			return nil, err
+		}
+		paths[kind] = path
+	}
+	return paths, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+// Package testing contains helpers for running qpm commands without a network: an
+// in-process registry client, a gRPC server on an in-memory connection and a builder for
+// local package repositories.
+package testing
+
+import (
+	"io/ioutil"
+	"log"
+	"sync"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+	"qpm.io/server"
+)
+
+const (
+	// User is the account used by FakeClient.Add and FakeClient.AddKey.
+	User     = "test@qpm.io"
+	password = "password"
+)
+
+// FakeClient is a msg.QpmClient which calls a registry server directly, without any
+// networking or serialization. It records the name of every call it receives.
+type FakeClient struct {
+	Server *server.Server
+	Store  *server.MemoryStore
+
+	mutex sync.Mutex
+	calls []string
+	token string
+}
+
+// NewFakeClient returns a client for a new, empty registry.
+func NewFakeClient() *FakeClient {
+	store := server.NewMemoryStore()
+	return &FakeClient{
+		Server: server.NewServer(store),
+		Store:  store,
+	}
+}
+
+// Calls returns the names of the methods that have been called, in order.
+func (c *FakeClient) Calls() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string{}, c.calls...)
+}
+
+// Add publishes packages to the registry as User, logging in first if needed. The calls
+// are not recorded.
+func (c *FakeClient) Add(packages ...*msg.Package) error {
+	token, err := c.login()
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range packages {
+		_, err := c.Server.Publish(context.Background(), &msg.PublishRequest{
+			PackageDescription: pkg,
+			Token:              token,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddKey uploads the armored public key to the registry as User, so that packages added
+// with Add can be verified with it. The call is not recorded.
+func (c *FakeClient) AddKey(publicKey string) error {
+	token, err := c.login()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Server.PublishKey(context.Background(), &msg.PublishKeyRequest{
+		Token:     token,
+		PublicKey: publicKey,
+	})
+	return err
+}
+
+// login returns the token of User, creating the account the first time.
+func (c *FakeClient) login() (string, error) {
+	c.mutex.Lock()
+	token := c.token
+	c.mutex.Unlock()
+
+	if token != "" {
+		return token, nil
+	}
+
+	response, err := c.Server.Login(context.Background(), &msg.LoginRequest{
+		Email:    User,
+		Password: password,
+		Create:   true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	c.mutex.Lock()
+	c.token = response.Token
+	c.mutex.Unlock()
+	return response.Token, nil
+}
+
+// Context returns a core.Context which uses the client and discards log output.
+func (c *FakeClient) Context() core.Context {
+	return NewContext(c)
+}
+
+// NewContext returns a core.Context for the given client which discards log output.
+func NewContext(client msg.QpmClient) core.Context {
+	return core.Context{
+		Log:    log.New(ioutil.Discard, "", 0),
+		Client: client,
+		Config: &core.Config{},
+	}
+}
+
+func (c *FakeClient) record(method string) {
+	c.mutex.Lock()
+	c.calls = append(c.calls, method)
+	c.mutex.Unlock()
+}
+
+func (c *FakeClient) Ping(ctx context.Context, in *msg.PingRequest, opts ...grpc.CallOption) (*msg.PingResponse, error) {
+	c.record("Ping")
+	return c.Server.Ping(ctx, in)
+}
+
+func (c *FakeClient) Publish(ctx context.Context, in *msg.PublishRequest, opts ...grpc.CallOption) (*msg.PublishResponse, error) {
+	c.record("Publish")
+	return c.Server.Publish(ctx, in)
+}
+
+func (c *FakeClient) GetDependencies(ctx context.Context, in *msg.DependencyRequest, opts ...grpc.CallOption) (*msg.DependencyResponse, error) {
+	c.record("GetDependencies")
+	return c.Server.GetDependencies(ctx, in)
+}
+
+func (c *FakeClient) Search(ctx context.Context, in *msg.SearchRequest, opts ...grpc.CallOption) (*msg.SearchResponse, error) {
+	c.record("Search")
+	return c.Server.Search(ctx, in)
+}
+
+func (c *FakeClient) List(ctx context.Context, in *msg.ListRequest, opts ...grpc.CallOption) (*msg.ListResponse, error) {
+	c.record("List")
+	return c.Server.List(ctx, in)
+}
+
+func (c *FakeClient) Login(ctx context.Context, in *msg.LoginRequest, opts ...grpc.CallOption) (*msg.LoginResponse, error) {
+	c.record("Login")
+	return c.Server.Login(ctx, in)
+}
+
+func (c *FakeClient) Info(ctx context.Context, in *msg.InfoRequest, opts ...grpc.CallOption) (*msg.InfoResponse, error) {
+	c.record("Info")
+	return c.Server.Info(ctx, in)
+}
+
+func (c *FakeClient) GetLicense(ctx context.Context, in *msg.LicenseRequest, opts ...grpc.CallOption) (*msg.LicenseResponse, error) {
+	c.record("GetLicense")
+	return c.Server.GetLicense(ctx, in)
+}
+
+func (c *FakeClient) PublishKey(ctx context.Context, in *msg.PublishKeyRequest, opts ...grpc.CallOption) (*msg.PublishKeyResponse, error) {
+	c.record("PublishKey")
+	return c.Server.PublishKey(ctx, in)
+}
+
+func (c *FakeClient) GetKey(ctx context.Context, in *msg.KeyRequest, opts ...grpc.CallOption) (*msg.KeyResponse, error) {
+	c.record("GetKey")
+	return c.Server.GetKey(ctx, in)
+}
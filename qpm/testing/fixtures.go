@@ -0,0 +1,214 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"golang.org/x/crypto/openpgp"
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/qpm/core"
+)
+
+// Fixture builds package repositories and projects in a temporary directory.
+type Fixture struct {
+	Dir string
+}
+
+// NewFixture creates a fixture in a new temporary directory. Remove deletes it again.
+func NewFixture() (*Fixture, error) {
+	dir, err := ioutil.TempDir("", "qpm-fixture-")
+	if err != nil {
+		return nil, err
+	}
+	return &Fixture{
+		Dir: dir,
+	}, nil
+}
+
+func (f *Fixture) Remove() error {
+	return os.RemoveAll(f.Dir)
+}
+
+// CacheDir returns a package cache directory inside the fixture. Setting XDG_CACHE_HOME to
+// this keeps installs from using the real cache.
+func (f *Fixture) CacheDir() string {
+	return filepath.Join(f.Dir, "cache")
+}
+
+// Release commits a new version of a package to its git repository, creating the repository
+// the first time, and returns the package description to publish. Each release contains a
+// qpm.json, a .pri file and any extra files given as name/content pairs.
+func (f *Fixture) Release(name string, label string, dependencies []string, files ...string) (*msg.Package, error) {
+	return f.release(nil, name, label, dependencies, files)
+}
+
+// SignedRelease is like Release, but the package is signed with signer: its fingerprint is
+// in qpm.json and the release contains the signature file.
+func (f *Fixture) SignedRelease(signer *openpgp.Entity, name string, label string, dependencies []string, files ...string) (*msg.Package, error) {
+	return f.release(signer, name, label, dependencies, files)
+}
+
+func (f *Fixture) release(signer *openpgp.Entity, name string, label string, dependencies []string, files []string) (*msg.Package, error) {
+	if len(files)%2 != 0 {
+		return nil, fmt.Errorf("files must be given as name/content pairs")
+	}
+
+	dir := filepath.Join(f.Dir, "repositories", name)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		if err = git(dir, "init", "-q"); err != nil {
+			return nil, err
+		}
+	}
+
+	pkg := &msg.Package{
+		Name:         name,
+		Description:  "The " + name + " package",
+		License:      msg.LicenseType_MIT,
+		Dependencies: dependencies,
+		PriFilename:  strings.Replace(name, ".", "_", -1) + ".pri",
+		Author: &msg.Package_Author{
+			Name:  "Test",
+			Email: User,
+		},
+		Repository: &msg.Package_Repository{
+			Type: msg.RepoType_GIT,
+			Url:  dir,
+		},
+		Version: &msg.Package_Version{
+			Label: label,
+		},
+	}
+	if signer != nil {
+		pkg.Version.Fingerprint = common.KeyFingerprint(signer)
+	}
+
+	if err := WritePackage(dir, pkg); err != nil {
+		return nil, err
+	}
+
+	files = append(files, pkg.PriFilename, "# "+name+"@"+label+"\n")
+	for i := 0; i < len(files); i += 2 {
+		path := filepath.Join(dir, files[i])
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, []byte(files[i+1]), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	if signer != nil {
+		if err := sign(dir, signer); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := git(dir, "add", "-A"); err != nil {
+		return nil, err
+	}
+	if err := git(dir, "commit", "-q", "--allow-empty", "-m", "Release "+label); err != nil {
+		return nil, err
+	}
+
+	out, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	pkg.Version.Revision = out
+
+	if pkg.Version.Hash, err = common.HashTree(dir); err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+// sign writes the signature of the hash of the files in dir to its signature file.
+func sign(dir string, signer *openpgp.Entity) error {
+	hash, err := common.HashTree(dir)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, core.SignatureFile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return openpgp.ArmoredDetachSign(file, signer, strings.NewReader(hash), nil)
+}
+
+// Project creates a directory containing a qpm.json with the given dependencies and
+// returns its path.
+func (f *Fixture) Project(name string, dependencies ...string) (string, error) {
+	dir := filepath.Join(f.Dir, "projects", name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	pkg := &msg.Package{
+		Dependencies: dependencies,
+	}
+	if err := WritePackage(dir, pkg); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// WritePackage writes pkg to the qpm.json file in dir.
+func WritePackage(dir string, pkg *msg.Package) error {
+	file, err := os.Create(filepath.Join(dir, core.PackageFile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	marshaller := &jsonpb.Marshaler{
+		Indent: "  ",
+	}
+	return marshaller.Marshal(file, pkg)
+}
+
+// Chdir changes the working directory, which is where the commands look for qpm.json,
+// and returns a function that changes it back.
+func Chdir(dir string) (func() error, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chdir(dir); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return os.Chdir(pwd)
+	}, nil
+}
+
+func git(dir string, args ...string) error {
+	_, err := gitOutput(dir, args...)
+	return err
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	args = append([]string{"-c", "user.name=Test", "-c", "user.email=" + User}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args[4:], " "), err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package testing
+
+import (
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	msg "qpm.io/common/messages"
+)
+
+const bufferSize = 1024 * 1024
+
+// TestServer serves a registry over gRPC on an in-memory connection, so that the client
+// side of the protocol is exercised without opening any sockets.
+type TestServer struct {
+	Client   msg.QpmClient
+	server   *grpc.Server
+	listener *bufconn.Listener
+	conn     *grpc.ClientConn
+}
+
+// NewTestServer starts serving srv and connects a client to it. Close must be called when
+// the server is no longer needed.
+func NewTestServer(srv msg.QpmServer) (*TestServer, error) {
+	listener := bufconn.Listen(bufferSize)
+
+	server := grpc.NewServer()
+	msg.RegisterQpmServer(server, srv)
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithInsecure(),
+		grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	)
+	if err != nil {
+		server.Stop()
+		return nil, err
+	}
+
+	return &TestServer{
+		Client:   msg.NewQpmClient(conn),
+		server:   server,
+		listener: listener,
+		conn:     conn,
+	}, nil
+}
+
+func (ts *TestServer) Close() error {
+	err := ts.conn.Close()
+	ts.server.Stop()
+	return err
+}
@@ -0,0 +1,342 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package vcs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+)
+
+const (
+	cacheEntryFile = "entry.json"
+	cacheSourceDir = "src"
+)
+
+// revisionPattern matches the commit ids that cache entries are stored under. Revisions come
+// from the registry or a lock file, so anything else could point outside the cache.
+var revisionPattern = regexp.MustCompile("^[0-9a-fA-F]{7,64}$")
+
+// repositoryPattern matches the names of the directories that path creates for each
+// repository, which are the only ones Clean removes.
+var repositoryPattern = regexp.MustCompile("^[0-9a-f]{16}$")
+
+// CacheEntry describes a single package revision stored in the Cache.
+type CacheEntry struct {
+	Name     string `json:"name"`
+	Url      string `json:"url"`
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Hash     string `json:"hash"`
+	Created  string `json:"created"`
+	// Path is the directory containing the entry.
+	Path string `json:"-"`
+}
+
+// SourceDir returns the directory containing the package files.
+func (e CacheEntry) SourceDir() string {
+	return filepath.Join(e.Path, cacheSourceDir)
+}
+
+// Verify checks that the cached files still have the hash they had when they were stored.
+func (e CacheEntry) Verify() error {
+	hash, err := common.HashTree(e.SourceDir())
+	if err != nil {
+		return err
+	}
+	if hash != e.Hash {
+		return fmt.Errorf("%s@%s has been modified", e.Name, e.Version)
+	}
+	return nil
+}
+
+// CopyTo recreates the cached files in destination. Files are copied rather than linked, so
+// that editing an installed package does not change the cache or other projects.
+func (e CacheEntry) CopyTo(destination string) error {
+	return copyTree(e.SourceDir(), destination)
+}
+
+// Cache is a store of package sources on the local machine, shared between projects and
+// keyed by repository URL and revision.
+type Cache struct {
+	Dir string
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{
+		Dir: dir,
+	}
+}
+
+func (c *Cache) path(repository *msg.Package_Repository, version *msg.Package_Version) (string, error) {
+	if !revisionPattern.MatchString(version.Revision) {
+		return "", fmt.Errorf("%q is not a commit id", version.Revision)
+	}
+	sum := sha256.Sum256([]byte(repository.Url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:8]), strings.ToLower(version.Revision)), nil
+}
+
+// Lookup returns the entry for the given revision or nil if it has not been cached. Entries
+// that are incomplete or stored under the wrong revision are removed. The files were checked
+// when they were stored and are not hashed again here; qpm cache verify does that.
+func (c *Cache) Lookup(repository *msg.Package_Repository, version *msg.Package_Version) *CacheEntry {
+	path, err := c.path(repository, version)
+	if err != nil {
+		return nil
+	}
+	entry, err := loadCacheEntry(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		os.RemoveAll(path)
+		return nil
+	}
+	if _, err = os.Stat(entry.SourceDir()); err != nil || !strings.EqualFold(entry.Revision, version.Revision) {
+		c.Remove(entry)
+		return nil
+	}
+	return entry
+}
+
+// Store populates a new cache entry by calling fetch with the directory that the package
+// should be written to. The entry only becomes visible once fetch succeeds and, if the
+// version has a hash, the fetched files match it.
+func (c *Cache) Store(repository *msg.Package_Repository, version *msg.Package_Version, fetch func(dir string) error) (*CacheEntry, error) {
+	path, err := c.path(repository, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempDir(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	entry := &CacheEntry{
+		Url:      repository.Url,
+		Version:  version.Label,
+		Revision: version.Revision,
+		Created:  time.Now().Format(time.RFC3339),
+		Path:     tmp,
+	}
+
+	if err = fetch(entry.SourceDir()); err != nil {
+		return nil, err
+	}
+
+	pkg, err := common.LoadPackage(entry.SourceDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	entry.Name = pkg.Name
+
+	if entry.Hash, err = common.HashTree(entry.SourceDir()); err != nil {
+		return nil, err
+	}
+	if version.Hash != "" && version.Hash != entry.Hash {
+		return nil, fmt.Errorf("The content does not match the hash it was published with (expected %s, got %s)", version.Hash, entry.Hash)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(filepath.Join(tmp, cacheEntryFile), data, 0644); err != nil {
+		return nil, err
+	}
+
+	// Another process may have stored the same revision in the meantime
+	os.RemoveAll(path)
+	if err = os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	entry.Path = path
+	return entry, nil
+}
+
+// Entries returns everything that is currently in the cache.
+func (c *Cache) Entries() ([]*CacheEntry, error) {
+	entries := []*CacheEntry{}
+
+	repos, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return entries, err
+	}
+
+	for _, repo := range repos {
+		if !repo.IsDir() {
+			continue
+		}
+		revisions, err := ioutil.ReadDir(filepath.Join(c.Dir, repo.Name()))
+		if err != nil {
+			return entries, err
+		}
+		for _, rev := range revisions {
+			// skip incomplete entries
+			if !rev.IsDir() || strings.HasPrefix(rev.Name(), ".") {
+				continue
+			}
+			entry, err := loadCacheEntry(filepath.Join(c.Dir, repo.Name(), rev.Name()))
+			if err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// Remove deletes a single entry from the cache.
+func (c *Cache) Remove(entry *CacheEntry) error {
+	return os.RemoveAll(entry.Path)
+}
+
+// Clean deletes every entry in the cache. Only the directories that the cache created are
+// removed, so files that share the directory with the cache are left alone.
+func (c *Cache) Clean() error {
+	repos, err := ioutil.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		if repo.IsDir() && repositoryPattern.MatchString(repo.Name()) {
+			if err = os.RemoveAll(filepath.Join(c.Dir, repo.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Only succeeds if nothing else is in the directory
+	os.Remove(c.Dir)
+	return nil
+}
+
+func loadCacheEntry(path string) (*CacheEntry, error) {
+	data, err := ioutil.ReadFile(filepath.Join(path, cacheEntryFile))
+	if err != nil {
+		return nil, err
+	}
+	entry := &CacheEntry{}
+	if err = json.Unmarshal(data, entry); err != nil {
+		return nil, err
+	}
+	entry.Path = path
+	return entry, nil
+}
+
+// CachedInstaller installs packages from a Cache, using another Installer to fetch any
+// revisions that are not cached yet. Without an Installer, only cached revisions can be
+// installed.
+type CachedInstaller struct {
+	installer Installer
+	cache     *Cache
+}
+
+func NewCachedInstaller(installer Installer, cache *Cache) *CachedInstaller {
+	return &CachedInstaller{
+		installer: installer,
+		cache:     cache,
+	}
+}
+
+func (ci *CachedInstaller) Install(repository *msg.Package_Repository, version *msg.Package_Version, destination string) (*common.PackageWrapper, error) {
+
+	entry := ci.cache.Lookup(repository, version)
+	if entry == nil && ci.installer == nil {
+		return nil, fmt.Errorf("revision %s of %s is not in the cache", version.Revision, repository.Url)
+	} else if entry == nil {
+		var err error
+		entry, err = ci.cache.Store(repository, version, func(dir string) error {
+			_, err := ci.installer.Install(repository, version, dir)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.RemoveAll(destination); err != nil {
+		return nil, err
+	}
+
+	if err := entry.CopyTo(destination); err != nil {
+		return nil, err
+	}
+
+	return common.LoadPackage(destination)
+}
+
+func copyTree(src string, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode())
+		}
+
+		return nil
+	})
+}
+
+func copyFile(src string, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,132 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package vcs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	msg "qpm.io/common/messages"
+)
+
+// storeFile stores a cache entry with a single file for the given revision.
+func storeFile(t *testing.T, cache *Cache, revision string) *CacheEntry {
+	repository := &msg.Package_Repository{Url: "https://example.com/" + revision + ".git"}
+	version := &msg.Package_Version{Label: "1.0.0", Revision: revision}
+	entry, err := cache.Store(repository, version, func(dir string) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, "file.h"), []byte("int file;\n"), 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func TestCacheCleanKeepsOtherFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qpm-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The cache directory is a setting, so it may be shared with anything
+	for _, other := range []string{"notes.txt", "projects/app/qpm.json"} {
+		path := filepath.Join(dir, filepath.FromSlash(other))
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := NewCache(dir)
+	entry := storeFile(t, cache, "0123456789abcdef0123456789abcdef01234567")
+
+	if err = cache.Clean(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(entry.Path); !os.IsNotExist(err) {
+		t.Errorf("%s was not removed: %v", entry.Path, err)
+	}
+	for _, other := range []string{"notes.txt", "projects/app/qpm.json"} {
+		if _, err = os.Stat(filepath.Join(dir, filepath.FromSlash(other))); err != nil {
+			t.Errorf("%s was removed: %v", other, err)
+		}
+	}
+}
+
+func TestCacheCleanRemovesEmptyDir(t *testing.T) {
+	base, err := ioutil.TempDir("", "qpm-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+
+	cache := NewCache(filepath.Join(base, "qpm"))
+	storeFile(t, cache, "0123456789abcdef0123456789abcdef01234567")
+
+	if err = cache.Clean(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(cache.Dir); !os.IsNotExist(err) {
+		t.Errorf("%s was not removed: %v", cache.Dir, err)
+	}
+}
+
+func TestCacheCopiesAreIndependent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qpm-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := NewCache(filepath.Join(dir, "cache"))
+	entry := storeFile(t, cache, "0123456789abcdef0123456789abcdef01234567")
+
+	vendor := filepath.Join(dir, "vendor")
+	if err = entry.CopyTo(vendor); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(vendor, "file.h"), []byte("int edited;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = entry.Verify(); err != nil {
+		t.Errorf("editing the installed copy changed the cache: %v", err)
+	}
+}
+
+func TestCacheStoreRejectsHashMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qpm-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := NewCache(dir)
+	repository := &msg.Package_Repository{Url: "https://example.com/alpha.git"}
+	version := &msg.Package_Version{
+		Label:    "1.0.0",
+		Revision: "0123456789abcdef0123456789abcdef01234567",
+		Hash:     "0000000000000000000000000000000000000000000000000000000000000000",
+	}
+	_, err = cache.Store(repository, version, func(dir string) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, "file.h"), []byte("int file;\n"), 0644)
+	})
+	if err == nil {
+		t.Fatal("expected content with the wrong hash to be rejected")
+	}
+	if entry := cache.Lookup(repository, version); entry != nil {
+		t.Errorf("the rejected content was cached in %s", entry.Path)
+	}
+}
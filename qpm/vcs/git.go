@@ -6,8 +6,10 @@ package vcs
 import (
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"qpm.io/common"
@@ -33,22 +35,12 @@ func (g *Git) Install(repository *msg.Package_Repository, version *msg.Package_V
 		return nil, err
 	}
 
-	pwd, err := os.Getwd()
+	err = g.checkoutRevision(destination, version.Revision)
 	if err != nil {
 		return nil, err
 	}
 
-	err = os.Chdir(destination)
-	if err != nil {
-		return nil, err
-	}
-
-	err = g.checkoutRevision(version.Revision)
-	if err != nil {
-		return nil, err
-	}
-
-	err = os.Chdir(pwd)
+	err = g.updateSubmodules(destination)
 	if err != nil {
 		return nil, err
 	}
@@ -72,16 +64,30 @@ func (g *Git) cloneRepository(url string, destdir string) error {
 
 	_, err := exec.Command("git", "clone", "--recursive", url, destdir).Output()
 	if err != nil {
-		return err
+		return commandError("git clone", err)
 	}
 	return nil
 }
 
-func (g *Git) checkoutRevision(revision string) error {
+func (g *Git) checkoutRevision(dir string, revision string) error {
 	//log.Print("git checkout ", revision)
-	_, err := exec.Command("git", "checkout", revision).Output()
+	cmd := exec.Command("git", "checkout", revision)
+	cmd.Dir = dir
+	_, err := cmd.Output()
 	if err != nil {
-		return err
+		return commandError("git checkout", err)
+	}
+	return nil
+}
+
+// updateSubmodules checks out the submodules at the commits recorded by the revision that is
+// checked out, since the recursive clone checks them out for the default branch instead.
+func (g *Git) updateSubmodules(dir string) error {
+	cmd := exec.Command("git", "submodule", "update", "--init", "--recursive")
+	cmd.Dir = dir
+	_, err := cmd.Output()
+	if err != nil {
+		return commandError("git submodule update", err)
 	}
 	return nil
 }
@@ -159,6 +165,82 @@ func (g *Git) RepositoryFileList() ([]string, error) {
 	return paths, nil
 }
 
+// Export writes the files committed in revision to destination the way Install checks them
+// out. Submodules are exported at the commits that revision records for them, so they must
+// be checked out in the working tree.
+func (g *Git) Export(revision string, destination string) error {
+	return g.export(".", revision, destination)
+}
+
+func (g *Git) export(dir string, revision string, destination string) error {
+
+	// Check the revision out through a temporary index, so that the same attributes and
+	// filters apply as for git checkout, while the working tree and index are left alone
+	tmp, err := ioutil.TempDir("", "qpm-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	prefix, err := filepath.Abs(destination)
+	if err != nil {
+		return err
+	}
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"))
+	for _, args := range [][]string{
+		{"read-tree", revision},
+		{"checkout-index", "--all", "--prefix=" + prefix + string(filepath.Separator)},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		if _, err = cmd.Output(); err != nil {
+			return commandError("git "+args[0], err)
+		}
+	}
+
+	submodules, err := g.submodules(dir, revision)
+	if err != nil {
+		return err
+	}
+	for path, commit := range submodules {
+		if yes, _ := exists(filepath.Join(dir, path, ".git")); !yes {
+			return fmt.Errorf("the submodule %s is not checked out, run git submodule update --init --recursive", path)
+		}
+		if err = os.MkdirAll(filepath.Join(destination, path), 0755); err != nil {
+			return err
+		}
+		if err = g.export(filepath.Join(dir, path), commit, filepath.Join(destination, path)); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// submodules returns the commits that revision records for its submodules by their paths.
+func (g *Git) submodules(dir string, revision string) (map[string]string, error) {
+	cmd := exec.Command("git", "ls-tree", "-r", "-z", revision)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, commandError("git ls-tree", err)
+	}
+
+	submodules := make(map[string]string)
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// Each entry is "<mode> <type> <object>\t<path>"
+		parts := strings.SplitN(entry, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fields := strings.Fields(parts[0])
+		if len(fields) == 3 && fields[1] == "commit" {
+			submodules[filepath.FromSlash(parts[1])] = fields[2]
+		}
+	}
+	return submodules, nil
+}
+
 func (g *Git) LastCommitRevision() (string, error) {
 	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
 	return strings.TrimSpace(string(out)), err
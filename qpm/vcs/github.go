@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -24,11 +25,24 @@ const (
 	TarSuffix = ".tar.gz"
 )
 
+// Limits on the contents of a downloaded tarball, to protect against archive bombs
+const (
+	DefaultMaxArchiveSize  = 512 << 20
+	DefaultMaxArchiveFiles = 50000
+)
+
 type GitHub struct {
+	// MaxSize is the maximum number of bytes extracted from a tarball
+	MaxSize int64
+	// MaxFiles is the maximum number of entries extracted from a tarball
+	MaxFiles int
 }
 
 func NewGitHub() *GitHub {
-	return &GitHub{}
+	return &GitHub{
+		MaxSize:  DefaultMaxArchiveSize,
+		MaxFiles: DefaultMaxArchiveFiles,
+	}
 }
 
 func (g *GitHub) Install(repository *msg.Package_Repository, version *msg.Package_Version, destination string) (*common.PackageWrapper, error) {
@@ -47,13 +61,9 @@ func (g *GitHub) Install(repository *msg.Package_Repository, version *msg.Packag
 	if err != nil {
 		return nil, err
 	}
+	defer os.Remove(fileName)
 
-	pkg, err := g.extract(fileName, fileDestination, destinationSuffix)
-	if err != nil {
-		return nil, err
-	}
-
-	return pkg, os.Remove(fileName)
+	return g.Extract(fileName, fileDestination, destinationSuffix)
 }
 
 func (g *GitHub) download(repository *msg.Package_Repository, version *msg.Package_Version, destination string) (fileName string, err error) {
@@ -113,14 +123,16 @@ func (g *GitHub) download(repository *msg.Package_Repository, version *msg.Packa
 	return fileName, nil
 }
 
-func (g *GitHub) extract(fileName string, destination string, suffix string) (*common.PackageWrapper, error) {
+// Extract unpacks the tarball in fileName and moves its top level directory to suffix
+// inside destination. Entries that would end up outside of the top level directory, links
+// that point outside of it, tarballs that exceed the size or file limits and packages that
+// use git submodules are rejected. Nothing is left behind in destination if extraction fails.
+func (g *GitHub) Extract(fileName string, destination string, suffix string) (*common.PackageWrapper, error) {
 
 	file, err := os.Open(fileName)
-
 	if err != nil {
 		return nil, err
 	}
-
 	defer file.Close()
 
 	var fileReader io.ReadCloser = file
@@ -133,66 +145,263 @@ func (g *GitHub) extract(fileName string, destination string, suffix string) (*c
 		defer fileReader.Close()
 	}
 
-	tarBallReader := tar.NewReader(fileReader)
+	// Extract to a temporary directory first so that a failure does not leave a partial
+	// package behind
+	tmp, err := ioutil.TempDir(destination, ".qpm-extract-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	topDir, err := g.extractAll(tar.NewReader(fileReader), tmp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Base(fileName), err)
+	}
+	if topDir == "" {
+		return nil, fmt.Errorf("%s: the tarball is empty", filepath.Base(fileName))
+	}
+
+	// GitHub leaves submodules out of its tarballs, so the package could never match the
+	// hash it was published with
+	if yes, _ := exists(filepath.Join(tmp, topDir, ".gitmodules")); yes {
+		return nil, fmt.Errorf("%s: the package uses git submodules, which need git to be installed", filepath.Base(fileName))
+	}
+
+	path := filepath.Join(destination, suffix)
+	if err = os.Rename(filepath.Join(tmp, topDir), path); err != nil {
+		return nil, err
+	}
+
+	return common.LoadPackage(path)
+}
+
+// extractAll writes the entries of the tarball to dir and returns the name of the top
+// level directory that they are all in.
+func (g *GitHub) extractAll(tarBallReader *tar.Reader, dir string) (string, error) {
 	var topDir string
+	var size int64
+	files := 0
+
+	// Work on the real path of dir so that links in the entries can be told apart from
+	// links in the path of dir itself
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
 
 	for {
 		header, err := tarBallReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		files++
+		if files > g.MaxFiles {
+			return "", fmt.Errorf("the tarball has more than %d files", g.MaxFiles)
+		}
+
+		name, err := archivePath(header.Name)
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
+			return "", err
+		}
+		if name == "." {
+			continue
 		}
 
-		filename := destination + string(filepath.Separator) + header.Name
+		// Every entry must be inside the same top level directory
+		top := strings.SplitN(name, string(filepath.Separator), 2)[0]
+		if topDir == "" {
+			topDir = top
+		} else if top != topDir {
+			return "", fmt.Errorf("%s is outside of the top level directory %s", header.Name, topDir)
+		}
+		root := filepath.Join(dir, topDir)
+		filename := filepath.Join(dir, name)
+
+		if header.Typeflag != tar.TypeDir || filename != root {
+			if err := checkParent(root, filename); err != nil {
+				return "", err
+			}
+		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			tokens := strings.Split(header.Name, "/")
-			topDir = tokens[0]
-			err = os.MkdirAll(filename, os.FileMode(header.Mode)) // or use 0755
-			if err != nil {
-				return nil, err
+			if err = os.MkdirAll(filename, 0755); err != nil {
+				return "", err
 			}
 
-		case tar.TypeReg:
-			writer, err := os.Create(filename)
-			if err != nil {
-				return nil, err
+		case tar.TypeReg, tar.TypeRegA:
+			size += header.Size
+			if size > g.MaxSize {
+				return "", fmt.Errorf("the tarball is larger than %d bytes", g.MaxSize)
 			}
-			io.Copy(writer, tarBallReader)
-			err = os.Chmod(filename, os.FileMode(header.Mode))
-			if err != nil {
-				return nil, err
+			if err = writeFile(filename, tarBallReader, header.Size, os.FileMode(header.Mode)&os.ModePerm); err != nil {
+				return "", err
 			}
-			writer.Close()
 
-		case tar.TypeXGlobalHeader:
-		// Ignore this
+		case tar.TypeSymlink:
+			target := filepath.FromSlash(header.Linkname)
+			if filepath.IsAbs(target) || !within(root, filepath.Join(filepath.Dir(filename), target)) {
+				return "", fmt.Errorf("the link %s points outside of the package", header.Name)
+			}
+			if err = os.Symlink(target, filename); err != nil {
+				return "", err
+			}
+
+		case tar.TypeLink:
+			target, err := archivePath(header.Linkname)
+			if err != nil {
+				return "", err
+			}
+			target = filepath.Join(dir, target)
+			if !within(root, target) {
+				return "", fmt.Errorf("the link %s points outside of the package", header.Name)
+			}
+			if err := checkParent(root, target); err != nil {
+				return "", err
+			}
+			if err = os.Link(target, filename); err != nil {
+				return "", err
+			}
 
 		default:
-			//i.Info("Unable to extract type : %c in file %s\n", header.Typeflag, filename)
+			// Devices, FIFOs and the like have no place in a source package
 		}
 	}
 
 	if topDir != "" {
+		if err := checkLinks(filepath.Join(dir, topDir)); err != nil {
+			return "", err
+		}
+	}
+	return topDir, nil
+}
+
+// archivePath cleans the name of a tarball entry and rejects names that are absolute or
+// that lead out of the directory the tarball is extracted to.
+func archivePath(name string) (string, error) {
+	path := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(path) || strings.HasPrefix(name, "/") || filepath.VolumeName(path) != "" {
+		return "", fmt.Errorf("the tarball contains the absolute path %s", name)
+	}
+	if path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the tarball contains the path %s which is outside of the package", name)
+	}
+	return path, nil
+}
+
+// within reports whether path is root or lexically inside it.
+func within(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
 
-		src := filepath.Join(destination, topDir)
+// checkParent makes sure that the parent directory of path is inside root and that none
+// of the links extracted so far are in the way, so that nothing is written through a link.
+// Both paths must be free of links outside of root.
+func checkParent(root string, path string) error {
+	dir := filepath.Dir(path)
+	if !within(root, dir) {
+		return fmt.Errorf("%s is outside of the package", path)
+	}
 
-		pkg, err := common.LoadPackage(src)
-		if err != nil {
-			return pkg, err
+	parent, err := filepath.EvalSymlinks(dir)
+	if os.IsNotExist(err) {
+		// Directories that are not in the tarball are created as needed, which fails
+		// if a link is in the way
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
 		}
+		parent, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		return err
+	}
+	if parent != dir {
+		return fmt.Errorf("%s is inside a link", path)
+	}
+	return nil
+}
 
-		path := filepath.Join(destination, suffix)
+// maxLinks is the number of links that resolve follows before giving up.
+const maxLinks = 255
 
-		if err = os.Rename(src, path); err != nil {
-			return pkg, err
+// checkLinks makes sure that every link below root, when followed through the links it
+// passes on the way, leads to a path inside root. Links whose targets do not exist are
+// checked too, since the target could be created later.
+func checkLinks(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
 		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		target, err := resolve(path, 0)
+		if err != nil {
+			return err
+		}
+		if !within(root, target) {
+			rel, _ := filepath.Rel(root, path)
+			return fmt.Errorf("the link %s points outside of the package", filepath.ToSlash(rel))
+		}
+		return nil
+	})
+}
+
+// resolve follows the links in path as far as they exist and returns the path they lead
+// to. Unlike filepath.EvalSymlinks, it also works when the final target does not exist.
+func resolve(path string, links int) (string, error) {
+	if links > maxLinks {
+		return "", fmt.Errorf("too many links in %s", path)
+	}
+
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	parent, base := filepath.Dir(path), filepath.Base(path)
+	if parent == path {
+		return path, nil
+	}
+	parent, err := resolve(parent, links+1)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Join(parent, base)
 
-		return pkg, err
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		// Either the path does not exist or it is not a link
+		return path, nil
+	}
+
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(parent, target)
 	}
+	return resolve(target, links+1)
+}
 
-	return nil, nil
+func writeFile(filename string, reader io.Reader, size int64, mode os.FileMode) error {
+	writer, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.CopyN(writer, reader, size); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
 }
@@ -0,0 +1,128 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package vcs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qpmtesting "qpm.io/qpm/testing"
+)
+
+// extract runs the tarball at path through extractAll into a new directory inside base.
+func extract(t *testing.T, g *GitHub, path string, base string) (string, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+
+	dir := filepath.Join(base, "extract")
+	if err = os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	topDir, err := g.extractAll(tar.NewReader(gz), dir)
+	return dir, topDir, err
+}
+
+func TestExtractRejectsUnsafeArchives(t *testing.T) {
+	f, err := qpmtesting.NewFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Remove()
+
+	outside, err := ioutil.TempDir("", "qpm-outside-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+
+	archives, err := f.UnsafeArchives(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &GitHub{MaxSize: 1 << 20, MaxFiles: 50}
+	for kind, path := range archives {
+		t.Run(kind, func(t *testing.T) {
+			base, err := ioutil.TempDir("", "qpm-extract-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(base)
+
+			if _, _, err = extract(t, g, path, base); err == nil {
+				t.Fatal("expected the tarball to be rejected")
+			}
+
+			// Nothing may have been written next to the extraction directory
+			entries, err := ioutil.ReadDir(base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.Name() != "extract" {
+					t.Errorf("%s was written outside of the package", entry.Name())
+				}
+			}
+			if entries, _ := ioutil.ReadDir(outside); len(entries) > 0 {
+				t.Errorf("%s was written outside of the package", filepath.Join(outside, entries[0].Name()))
+			}
+		})
+	}
+}
+
+func TestExtractKeepsLinksInsideThePackage(t *testing.T) {
+	f, err := qpmtesting.NewFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Remove()
+
+	path, err := f.Archive("safe.tar.gz",
+		qpmtesting.Dir("pkg/"),
+		qpmtesting.File("pkg/qpm.json", `{"name": "io.qpm.safe"}`),
+		qpmtesting.File("pkg/src/safe.h", "int safe;\n"),
+		qpmtesting.Symlink("pkg/include", "src"),
+		qpmtesting.Symlink("pkg/src/alias.h", "../include/safe.h"),
+		qpmtesting.Symlink("pkg/generated.h", "build/generated.h"),
+		qpmtesting.Hardlink("pkg/copy.h", "pkg/src/safe.h"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := ioutil.TempDir("", "qpm-extract-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+
+	dir, topDir, err := extract(t, NewGitHub(), path, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topDir != "pkg" {
+		t.Errorf("expected the top level directory pkg, got %q", topDir)
+	}
+	for _, file := range []string{"src/alias.h", "include/safe.h", "copy.h"} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, topDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Error(err)
+		} else if string(content) != "int safe;\n" {
+			t.Errorf("unexpected content in %s: %q", file, content)
+		}
+	}
+}
@@ -46,7 +46,7 @@ func (m *Mercurial) Test() error {
 func (m *Mercurial) cloneRepository(url string, revision string, destdir string) error {
 	_, err := exec.Command("hg", "clone", "-r", revision, url, destdir).Output()
 	if err != nil {
-		return err
+		return commandError("hg clone", err)
 	}
 	return nil
 }
@@ -90,6 +90,18 @@ func (m *Mercurial) RepositoryFileList() ([]string, error) {
 	return paths, nil
 }
 
+// Export writes the files committed in revision to destination, including those of its
+// subrepositories, which the clone done by Install checks out too.
+func (m *Mercurial) Export(revision string, destination string) error {
+	// Leave out .hg_archival.txt, which an installed package does not have
+	_, err := exec.Command("hg", "--config", "ui.archivemeta=false", "archive", "--subrepos",
+		"--type", "files", "--rev", revision, destination).Output()
+	if err != nil {
+		return commandError("hg archive", err)
+	}
+	return nil
+}
+
 func (m *Mercurial) LastCommitRevision() (string, error) {
 	out, err := exec.Command("hg", "log", "--template", "{node}", "--limit", "1").Output()
 	return strings.TrimSpace(string(out)), err
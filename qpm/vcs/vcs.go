@@ -6,6 +6,8 @@ package vcs
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"strings"
 
 	"qpm.io/common"
 	msg "qpm.io/common/messages"
@@ -16,7 +18,23 @@ type Installer interface {
 	Install(repository *msg.Package_Repository, version *msg.Package_Version, destination string) (*common.PackageWrapper, error)
 }
 
-func CreateInstaller(repository *msg.Package_Repository) (Installer, error) {
+// CreateInstaller returns an installer for the repository which fetches packages through
+// the local package cache in cacheDir.
+func CreateInstaller(repository *msg.Package_Repository, cacheDir string) (Installer, error) {
+	installer, err := createFetcher(repository)
+	if err != nil {
+		return nil, err
+	}
+	return NewCachedInstaller(installer, NewCache(cacheDir)), nil
+}
+
+// CreateOfflineInstaller returns an installer that only installs packages which are already
+// in the local package cache in cacheDir.
+func CreateOfflineInstaller(cacheDir string) Installer {
+	return NewCachedInstaller(nil, NewCache(cacheDir))
+}
+
+func createFetcher(repository *msg.Package_Repository) (Installer, error) {
 
 	switch repository.Type {
 	case msg.RepoType_GIT:
@@ -50,6 +68,8 @@ type Publisher interface {
 	LastCommitAuthorName() (string, error)
 	LastCommitEmail() (string, error)
 	RepositoryFileList() ([]string, error)
+	// Export writes the files committed in revision to destination, which must exist
+	Export(revision string, destination string) error
 }
 
 func CreatePublisher(repository *msg.Package_Repository) (Publisher, error) {
@@ -74,6 +94,15 @@ func CreatePublisher(repository *msg.Package_Repository) (Publisher, error) {
 	return nil, fmt.Errorf("Repository type %s is not supported", msg.RepoType_name[int32(repository.Type)])
 }
 
+// commandError includes the error output of a failed command in the error, since the exit
+// status alone says very little about what went wrong.
+func commandError(command string, err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%s: %s", command, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return fmt.Errorf("%s: %v", command, err)
+}
+
 func exists(path string) (bool, error) {
 	_, err := os.Stat(path)
 	if err == nil {
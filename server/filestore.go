@@ -0,0 +1,113 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// FileStore is a Store which keeps the registry in memory and writes all of it to a single
+// JSON file whenever something changes. This is plenty for a private registry with a
+// modest number of packages.
+type FileStore struct {
+	*MemoryStore
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStore opens the registry stored in the file at path. The file is created the first
+// time something is stored.
+func NewFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &store.MemoryStore.data); err != nil {
+		return nil, err
+	}
+
+	// Older files may not have all of the maps
+	if store.data.Packages == nil {
+		store.data.Packages = make(map[string][]*Release)
+	}
+	if store.data.Users == nil {
+		store.data.Users = make(map[string]*User)
+	}
+	if store.data.Tokens == nil {
+		store.data.Tokens = make(map[string]string)
+	}
+	if store.data.Keys == nil {
+		store.data.Keys = make(map[string]*Key)
+	}
+
+	return store, nil
+}
+
+func (f *FileStore) AddRelease(release *Release) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.MemoryStore.AddRelease(release); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *FileStore) AddUser(user *User) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.MemoryStore.AddUser(user); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *FileStore) AddToken(token string, email string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.MemoryStore.AddToken(token, email); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *FileStore) PutKey(key *Key) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.MemoryStore.PutKey(key); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// save writes the registry to a temporary file first so that a crash never leaves a
+// truncated registry behind.
+func (f *FileStore) save() error {
+	f.MemoryStore.mutex.RLock()
+	data, err := json.MarshalIndent(f.MemoryStore.data, "", "  ")
+	f.MemoryStore.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := f.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
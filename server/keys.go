@@ -0,0 +1,93 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+)
+
+// PublishKey stores a public key that the user signs packages with, so that installs can
+// fetch it. A key belongs to the first user who uploads it, who may upload it again to
+// add new signatures or subkeys.
+func (s *Server) PublishKey(ctx context.Context, req *msg.PublishKeyRequest) (*msg.PublishKeyResponse, error) {
+	email, err := s.authenticate(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(req.PublicKey))
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "could not read the key: %v", err)
+	}
+	if len(entities) != 1 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "exactly one key must be given, got %d", len(entities))
+	}
+
+	entity := entities[0]
+	if entity.PrivateKey != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "the key contains a private key, only upload the public key")
+	}
+	if len(entity.Revocations) > 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "the key has been revoked")
+	}
+
+	fingerprint := common.KeyFingerprint(entity)
+	publicKey, err := common.ArmoredPublicKey(entity)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	err = s.store.PutKey(&Key{
+		Fingerprint: fingerprint,
+		PublicKey:   publicKey,
+		Uploaded:    time.Now().UTC(),
+		Owner:       email,
+	})
+	if err == ErrNotOwner {
+		return nil, grpc.Errorf(codes.PermissionDenied, "the key %s belongs to another user", fingerprint)
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	return &msg.PublishKeyResponse{Fingerprint: fingerprint}, nil
+}
+
+// GetKey returns the public key with the given fingerprint. If a package is given, the key
+// must belong to the user who publishes it so that a key uploaded by someone else cannot be
+// passed off as the publisher's.
+func (s *Server) GetKey(ctx context.Context, req *msg.KeyRequest) (*msg.KeyResponse, error) {
+	fingerprint := common.NormalizeFingerprint(req.Fingerprint)
+
+	key, err := s.store.Key(fingerprint)
+	if err == ErrNotFound {
+		return nil, grpc.Errorf(codes.NotFound, "the key %s has not been uploaded", fingerprint)
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	if req.PackageName != "" {
+		releases, err := s.store.Releases(req.PackageName)
+		if err == ErrNotFound {
+			return nil, grpc.Errorf(codes.NotFound, "%s has not been published", req.PackageName)
+		} else if err != nil {
+			return nil, internalError(err)
+		}
+		if releases[0].Publisher != key.Owner {
+			return nil, grpc.Errorf(codes.PermissionDenied, "the key %s does not belong to the publisher of %s", fingerprint, req.PackageName)
+		}
+	}
+
+	return &msg.KeyResponse{
+		Fingerprint: key.Fingerprint,
+		PublicKey:   key.PublicKey,
+	}, nil
+}
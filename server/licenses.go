@@ -0,0 +1,115 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	msg "qpm.io/common/messages"
+)
+
+// licenses contains the license texts that are available without a LicenseDir. Other
+// licenses can be added by putting templates in the LicenseDir.
+var licenses = map[msg.LicenseType]string{
+	msg.LicenseType_MIT: `The MIT License (MIT)
+
+Copyright (c) {{.Year}} {{.Author}}
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`,
+	msg.LicenseType_UNLICENSE: `This is free and unencumbered software released into the public domain.
+
+Anyone is free to copy, modify, publish, use, compile, sell, or
+distribute this software, either in source code form or as a compiled
+binary, for any purpose, commercial or non-commercial, and by any
+means.
+
+In jurisdictions that recognize copyright laws, the author or authors
+of this software dedicate any and all copyright interest in the
+software to the public domain. We make this dedication for the benefit
+of the public at large and to the detriment of our heirs and
+successors. We intend this dedication to be an overt act of
+relinquishment in perpetuity of all present and future rights to this
+software under copyright law.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+IN NO EVENT SHALL THE AUTHORS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+OTHER DEALINGS IN THE SOFTWARE.
+
+For more information, please refer to <http://unlicense.org>
+`,
+}
+
+// license returns the license text for a package. A template in LicenseDir named after the
+// license, e.g. APACHE_2_0.txt, takes precedence over the built-in texts. Templates can use
+// {{.Year}}, {{.Author}} and {{.Package}}.
+func (s *Server) license(pkg *msg.Package) (string, error) {
+	text, ok := licenses[pkg.License]
+
+	if s.LicenseDir != "" {
+		data, err := ioutil.ReadFile(filepath.Join(s.LicenseDir, pkg.License.String()+".txt"))
+		if err == nil {
+			text, ok = string(data), true
+		} else if !os.IsNotExist(err) {
+			return "", internalError(err)
+		}
+	}
+
+	if !ok {
+		return "", grpc.Errorf(codes.NotFound, "no license text is available for %s", pkg.License.String())
+	}
+
+	tmpl, err := template.New("license").Parse(text)
+	if err != nil {
+		return "", internalError(err)
+	}
+
+	author := ""
+	if pkg.Author != nil {
+		author = pkg.Author.Name
+	}
+
+	var body bytes.Buffer
+	err = tmpl.Execute(&body, struct {
+		Year    int
+		Author  string
+		Package *msg.Package
+	}{
+		Year:    time.Now().Year(),
+		Author:  author,
+		Package: pkg,
+	})
+	if err != nil {
+		return "", internalError(err)
+	}
+
+	return body.String(), nil
+}
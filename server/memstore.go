@@ -0,0 +1,137 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"sort"
+	"sync"
+)
+
+// storeData is everything kept by the registry.
+type storeData struct {
+	Packages map[string][]*Release `json:"packages"`
+	Users    map[string]*User      `json:"users"`
+	Tokens   map[string]string     `json:"tokens"`
+	Keys     map[string]*Key       `json:"keys"`
+}
+
+// MemoryStore is a Store which keeps everything in memory. It is mostly useful for tests.
+type MemoryStore struct {
+	mutex sync.RWMutex
+	data  storeData
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: storeData{
+			Packages: make(map[string][]*Release),
+			Users:    make(map[string]*User),
+			Tokens:   make(map[string]string),
+			Keys:     make(map[string]*Key),
+		},
+	}
+}
+
+func (m *MemoryStore) Names() ([]string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	names := make([]string, 0, len(m.data.Packages))
+	for name := range m.data.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *MemoryStore) Releases(name string) ([]*Release, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	releases, ok := m.data.Packages[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]*Release{}, releases...), nil
+}
+
+func (m *MemoryStore) AddRelease(release *Release) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	name := release.Package.Name
+	if releases := m.data.Packages[name]; len(releases) > 0 && releases[0].Publisher != release.Publisher {
+		return ErrNotOwner
+	}
+	for _, r := range m.data.Packages[name] {
+		if r.Package.Version.Label == release.Package.Version.Label {
+			return ErrExists
+		}
+	}
+	m.data.Packages[name] = append(m.data.Packages[name], release)
+	return nil
+}
+
+func (m *MemoryStore) User(email string) (*User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	user, ok := m.data.Users[email]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+func (m *MemoryStore) AddUser(user *User) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.data.Users[user.Email]; ok {
+		return ErrExists
+	}
+	m.data.Users[user.Email] = user
+	return nil
+}
+
+func (m *MemoryStore) AddToken(token string, email string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.data.Tokens[token] = email
+	return nil
+}
+
+func (m *MemoryStore) TokenUser(token string) (string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	email, ok := m.data.Tokens[token]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return email, nil
+}
+
+func (m *MemoryStore) Key(fingerprint string) (*Key, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	key, ok := m.data.Keys[fingerprint]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
+
+func (m *MemoryStore) PutKey(key *Key) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if existing, ok := m.data.Keys[key.Fingerprint]; ok && existing.Owner != key.Owner {
+		return ErrNotOwner
+	}
+	m.data.Keys[key.Fingerprint] = key
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	msg "qpm.io/common/messages"
+	"qpm.io/server"
+)
+
+func main() {
+	address := flag.String("address", ":7000", "The address to listen on")
+	data := flag.String("data", "registry.json", "The file the registry is stored in")
+	memory := flag.Bool("memory", false, "Keep the registry in memory only")
+	licenses := flag.String("licenses", "", "A directory of license templates named after the license, e.g. MIT.txt")
+	cert := flag.String("tls-cert", "", "The TLS certificate file")
+	key := flag.String("tls-key", "", "The TLS key file")
+
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, `
+qpm-server is a qpm package registry
+
+Usage:
+	qpm-server [options]
+
+Clients can use the registry by setting SERVER to its address, and NO_TLS=1 if it is
+started without a certificate.
+
+Options:`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var store server.Store
+	if *memory {
+		store = server.NewMemoryStore()
+	} else {
+		var err error
+		store, err = server.NewFileStore(*data)
+		if err != nil {
+			log.Fatalf("Could not open %s: %v", *data, err)
+		}
+	}
+
+	var options []grpc.ServerOption
+	if *cert != "" || *key != "" {
+		creds, err := credentials.NewServerTLSFromFile(*cert, *key)
+		if err != nil {
+			log.Fatalf("Could not load the TLS certificate: %v", err)
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		log.Fatalf("Could not listen on %s: %v", *address, err)
+	}
+
+	srv := server.NewServer(store)
+	srv.LicenseDir = *licenses
+
+	s := grpc.NewServer(options...)
+	msg.RegisterQpmServer(s, srv)
+
+	log.Printf("Listening on %s", listener.Addr())
+	log.Fatal(s.Serve(listener))
+}
@@ -0,0 +1,373 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+	"qpm.io/common/semver"
+)
+
+// requestRoot is the name used for the requester in dependency conflicts.
+const requestRoot = "request"
+
+// copyleft lists the licenses which are worth a warning when they are pulled into a project
+// under a different license.
+var copyleft = map[msg.LicenseType]bool{
+	msg.LicenseType_AGPL_3_0: true,
+	msg.LicenseType_GPL_2_0:  true,
+	msg.LicenseType_GPL_3_0:  true,
+}
+
+// Server implements the Qpm gRPC service on top of a Store.
+type Server struct {
+	store Store
+	// LicenseDir optionally contains license templates that are used instead of the
+	// built-in ones. See GetLicense.
+	LicenseDir string
+}
+
+func NewServer(store Store) *Server {
+	return &Server{
+		store: store,
+	}
+}
+
+func (s *Server) Ping(ctx context.Context, req *msg.PingRequest) (*msg.PingResponse, error) {
+	return &msg.PingResponse{}, nil
+}
+
+// Login checks the user's password and returns a new token which can be used to publish
+// packages. Unknown users are created if req.Create is set, otherwise codes.NotFound is
+// returned so that the client can offer to create one.
+func (s *Server) Login(ctx context.Context, req *msg.LoginRequest) (*msg.LoginResponse, error) {
+	if req.Email == "" || req.Password == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "an email and a password are required")
+	}
+
+	user, err := s.store.User(req.Email)
+	if err == ErrNotFound {
+		if !req.Create {
+			return nil, grpc.Errorf(codes.NotFound, "user %s does not exist", req.Email)
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		user = &User{
+			Email:    req.Email,
+			Password: hash,
+		}
+		if err = s.store.AddUser(user); err != nil {
+			return nil, internalError(err)
+		}
+	} else if err != nil {
+		return nil, internalError(err)
+	} else if bcrypt.CompareHashAndPassword(user.Password, []byte(req.Password)) != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "invalid password")
+	}
+
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return nil, internalError(err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err = s.store.AddToken(hashToken(token), user.Email); err != nil {
+		return nil, internalError(err)
+	}
+
+	return &msg.LoginResponse{Token: token}, nil
+}
+
+// Publish adds a new release of a package. The first user to publish a package owns it and
+// is the only one allowed to publish new releases.
+func (s *Server) Publish(ctx context.Context, req *msg.PublishRequest) (*msg.PublishResponse, error) {
+	email, err := s.authenticate(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := req.PackageDescription
+	if pkg == nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "no package was given")
+	}
+	if err = (&common.PackageWrapper{Package: pkg}).Validate(); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if pkg.Repository == nil || pkg.Repository.Url == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "the package has no repository")
+	}
+
+	err = s.store.AddRelease(&Release{
+		Package:   pkg,
+		Published: time.Now().UTC(),
+		Publisher: email,
+	})
+	if err == ErrNotOwner {
+		return nil, grpc.Errorf(codes.PermissionDenied, "%s is owned by another user", pkg.Name)
+	} else if err == ErrExists {
+		return nil, grpc.Errorf(codes.AlreadyExists, "%s@%s has already been published", pkg.Name, pkg.Version.Label)
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	return &msg.PublishResponse{}, nil
+}
+
+// GetDependencies resolves the requested packages and all of their dependencies.
+func (s *Server) GetDependencies(ctx context.Context, req *msg.DependencyRequest) (*msg.DependencyResponse, error) {
+	dependencies, err := s.resolve(req.PackageNames)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &msg.DependencyResponse{
+		Dependencies: dependencies,
+	}
+
+	for _, d := range dependencies {
+		release, err := s.release(d.Name, d.Version.Label)
+		if err != nil {
+			return nil, err
+		}
+		license := release.Package.License
+		if copyleft[license] && license != req.CompatLicense {
+			response.Messages = append(response.Messages, &msg.DependencyMessage{
+				Type:   msg.MessageType_WARNING,
+				Title:  d.Name + " is licensed under " + license.String(),
+				Body:   "This may not be compatible with the " + req.CompatLicense.String() + " license of your project.",
+				Prompt: true,
+			})
+		}
+	}
+
+	return response, nil
+}
+
+func (s *Server) Search(ctx context.Context, req *msg.SearchRequest) (*msg.SearchResponse, error) {
+	query := strings.ToLower(req.PackageName)
+
+	results, err := s.results(func(pkg *msg.Package) bool {
+		return strings.Contains(strings.ToLower(pkg.Name), query) ||
+			strings.Contains(strings.ToLower(pkg.Description), query)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &msg.SearchResponse{Results: results}, nil
+}
+
+func (s *Server) List(ctx context.Context, req *msg.ListRequest) (*msg.ListResponse, error) {
+	results, err := s.results(func(pkg *msg.Package) bool {
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &msg.ListResponse{Results: results}, nil
+}
+
+// Info returns the latest release of a package along with all of its versions. Install
+// statistics are not collected.
+func (s *Server) Info(ctx context.Context, req *msg.InfoRequest) (*msg.InfoResponse, error) {
+	info, err := s.info(req.PackageName)
+	if err != nil {
+		return nil, err
+	}
+
+	// A package whose dependencies cannot be resolved is still worth describing
+	if len(info.Package.Dependencies) > 0 {
+		info.Dependencies, _ = s.resolve(info.Package.Dependencies)
+	}
+
+	return info, nil
+}
+
+func (s *Server) GetLicense(ctx context.Context, req *msg.LicenseRequest) (*msg.LicenseResponse, error) {
+	if req.Package == nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "no package was given")
+	}
+
+	body, err := s.license(req.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	return &msg.LicenseResponse{Body: body}, nil
+}
+
+// info returns the registry entry of a package without resolving its dependencies.
+func (s *Server) info(name string) (*msg.InfoResponse, error) {
+	releases, err := s.store.Releases(name)
+	if err == ErrNotFound {
+		return nil, grpc.Errorf(codes.NotFound, "package %s does not exist", name)
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	info := &msg.InfoResponse{
+		Package: latest(releases).Package,
+	}
+
+	for _, r := range releases {
+		info.Versions = append(info.Versions, &msg.VersionInfo{
+			Version:       r.Package.Version,
+			DatePublished: r.Published.Format(time.RFC3339),
+		})
+	}
+	sort.Sort(sort.Reverse(byVersion(info.Versions)))
+
+	return info, nil
+}
+
+// release returns a specific release of a package.
+func (s *Server) release(name string, label string) (*Release, error) {
+	releases, err := s.store.Releases(name)
+	if err == ErrNotFound {
+		return nil, grpc.Errorf(codes.NotFound, "package %s does not exist", name)
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	for _, r := range releases {
+		if r.Package.Version.Label == label {
+			return r, nil
+		}
+	}
+	return nil, grpc.Errorf(codes.NotFound, "version %s of %s does not exist", label, name)
+}
+
+func (s *Server) resolve(dependencies []string) ([]*msg.Dependency, error) {
+	resolution, err := common.NewResolver(&storeSource{s}).Resolve(requestRoot, dependencies)
+	if err != nil {
+		if _, conflict := err.(*common.ConflictError); conflict {
+			return nil, grpc.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+		if grpc.Code(err) != codes.Unknown {
+			return nil, err
+		}
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return resolution.Dependencies, nil
+}
+
+// results returns a search result for the latest release of each package accepted by
+// the filter.
+func (s *Server) results(filter func(pkg *msg.Package) bool) ([]*msg.SearchResult, error) {
+	names, err := s.store.Names()
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	var results []*msg.SearchResult
+	for _, name := range names {
+		releases, err := s.store.Releases(name)
+		if err != nil {
+			return nil, internalError(err)
+		}
+
+		pkg := latest(releases).Package
+		if !filter(pkg) {
+			continue
+		}
+
+		results = append(results, &msg.SearchResult{
+			Name:        pkg.Name,
+			Version:     pkg.Version.Label,
+			Author:      pkg.Author,
+			Description: pkg.Description,
+			License:     pkg.License,
+			Webpage:     pkg.Webpage,
+		})
+	}
+
+	return results, nil
+}
+
+// storeSource provides the package information used by common.Resolver from the Store.
+type storeSource struct {
+	server *Server
+}
+
+func (s *storeSource) Info(name string) (*msg.InfoResponse, error) {
+	return s.server.info(name)
+}
+
+// Dependencies returns the dependencies declared by the given release. Unlike the client,
+// the server knows the dependencies of every release.
+func (s *storeSource) Dependencies(info *msg.InfoResponse, version *msg.Package_Version) ([]string, bool, error) {
+	release, err := s.server.release(info.Package.Name, version.Label)
+	if err != nil {
+		return nil, false, err
+	}
+	return release.Package.Dependencies, true, nil
+}
+
+// latest returns the release with the highest version. Pre-releases are only returned if
+// nothing else has been published.
+func latest(releases []*Release) *Release {
+	var newest *Release
+	var newestVersion *semver.Version
+	for _, r := range releases {
+		v, err := semver.Parse(r.Package.Version.Label)
+		if err != nil || v.IsPreRelease() {
+			continue
+		}
+		if newestVersion == nil || newestVersion.LessThan(v) {
+			newest, newestVersion = r, v
+		}
+	}
+	if newest == nil {
+		return releases[len(releases)-1]
+	}
+	return newest
+}
+
+// authenticate returns the email of the user that the token from Login belongs to.
+func (s *Server) authenticate(token string) (string, error) {
+	email, err := s.store.TokenUser(hashToken(token))
+	if err == ErrNotFound {
+		return "", grpc.Errorf(codes.Unauthenticated, "invalid token, please log in again")
+	} else if err != nil {
+		return "", internalError(err)
+	}
+	return email, nil
+}
+
+// hashToken is used so that the store never contains usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func internalError(err error) error {
+	return grpc.Errorf(codes.Internal, "%v", err)
+}
+
+type byVersion []*msg.VersionInfo
+
+func (v byVersion) Len() int      { return len(v) }
+func (v byVersion) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v byVersion) Less(i, j int) bool {
+	a, errA := semver.Parse(v[i].Version.Label)
+	b, errB := semver.Parse(v[j].Version.Label)
+	if errA != nil || errB != nil {
+		return v[i].Version.Label < v[j].Version.Label
+	}
+	return a.LessThan(b)
+}
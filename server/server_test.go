@@ -0,0 +1,143 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"qpm.io/common"
+	msg "qpm.io/common/messages"
+)
+
+// login returns a token for the user with the given email, creating the account.
+func login(t *testing.T, s *Server, email string) string {
+	response, err := s.Login(context.Background(), &msg.LoginRequest{
+		Email:    email,
+		Password: "secret",
+		Create:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Token
+}
+
+// publish publishes a minimal package as the user the token belongs to.
+func publish(s *Server, token string, name string, label string) error {
+	_, err := s.Publish(context.Background(), &msg.PublishRequest{
+		Token: token,
+		PackageDescription: &msg.Package{
+			Name: name,
+			Author: &msg.Package_Author{
+				Name:  "Test",
+				Email: "test@qpm.io",
+			},
+			Repository: &msg.Package_Repository{
+				Type: msg.RepoType_GIT,
+				Url:  "https://example.com/" + name + ".git",
+			},
+			Version: &msg.Package_Version{
+				Label:    label,
+				Revision: "0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+	})
+	return err
+}
+
+// expectCode fails the test unless err is a gRPC error with the given code.
+func expectCode(t *testing.T, err error, code codes.Code) {
+	if grpc.Code(err) != code {
+		t.Errorf("expected %v, got %v", code, err)
+	}
+}
+
+func TestPublishChecksOwner(t *testing.T) {
+	s := NewServer(NewMemoryStore())
+	alice := login(t, s, "alice@qpm.io")
+	bob := login(t, s, "bob@qpm.io")
+
+	if err := publish(s, alice, "io.qpm.alpha", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	expectCode(t, publish(s, bob, "io.qpm.alpha", "1.1.0"), codes.PermissionDenied)
+	expectCode(t, publish(s, alice, "io.qpm.alpha", "1.0.0"), codes.AlreadyExists)
+	expectCode(t, publish(s, "", "io.qpm.alpha", "1.1.0"), codes.Unauthenticated)
+	if err := publish(s, alice, "io.qpm.alpha", "1.1.0"); err != nil {
+		t.Errorf("expected the owner to publish a new version, got %v", err)
+	}
+}
+
+func TestLoginStoresHashedTokens(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewServer(store)
+	token := login(t, s, "alice@qpm.io")
+
+	if _, err := store.TokenUser(token); err != ErrNotFound {
+		t.Errorf("expected the token not to be stored as it is, got %v", err)
+	}
+	if email, err := store.TokenUser(hashToken(token)); err != nil || email != "alice@qpm.io" {
+		t.Errorf("expected the hash of the token to be stored, got %q, %v", email, err)
+	}
+
+	// The stored hash cannot be used as a token
+	expectCode(t, publish(s, hashToken(token), "io.qpm.alpha", "1.0.0"), codes.Unauthenticated)
+	if err := publish(s, token, "io.qpm.alpha", "1.0.0"); err != nil {
+		t.Errorf("expected the token to work, got %v", err)
+	}
+
+	_, err := s.Login(context.Background(), &msg.LoginRequest{Email: "alice@qpm.io", Password: "wrong"})
+	expectCode(t, err, codes.Unauthenticated)
+	_, err = s.Login(context.Background(), &msg.LoginRequest{Email: "bob@qpm.io", Password: "secret"})
+	expectCode(t, err, codes.NotFound)
+}
+
+func TestGetKeyChecksOwner(t *testing.T) {
+	s := NewServer(NewMemoryStore())
+	alice := login(t, s, "alice@qpm.io")
+	bob := login(t, s, "bob@qpm.io")
+
+	entity, err := openpgp.NewEntity("Alice", "", "alice@qpm.io", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := common.ArmoredPublicKey(entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := common.KeyFingerprint(entity)
+
+	if _, err = s.PublishKey(context.Background(), &msg.PublishKeyRequest{Token: alice, PublicKey: publicKey}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.PublishKey(context.Background(), &msg.PublishKeyRequest{Token: bob, PublicKey: publicKey})
+	expectCode(t, err, codes.PermissionDenied)
+
+	if err = publish(s, alice, "io.qpm.alpha", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err = publish(s, bob, "io.qpm.beta", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := s.GetKey(context.Background(), &msg.KeyRequest{Fingerprint: fingerprint, PackageName: "io.qpm.alpha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Fingerprint != fingerprint || response.PublicKey != publicKey {
+		t.Errorf("expected the key %s, got %s", fingerprint, response.Fingerprint)
+	}
+
+	_, err = s.GetKey(context.Background(), &msg.KeyRequest{Fingerprint: fingerprint, PackageName: "io.qpm.beta"})
+	expectCode(t, err, codes.PermissionDenied)
+	_, err = s.GetKey(context.Background(), &msg.KeyRequest{Fingerprint: fingerprint, PackageName: "io.qpm.gamma"})
+	expectCode(t, err, codes.NotFound)
+	_, err = s.GetKey(context.Background(), &msg.KeyRequest{Fingerprint: strings.Repeat("0", 40)})
+	expectCode(t, err, codes.NotFound)
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"errors"
+	"time"
+
+	msg "qpm.io/common/messages"
+)
+
+var (
+	// ErrNotFound is returned by a Store when the requested item does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrExists is returned by a Store when adding an item that already exists.
+	ErrExists = errors.New("already exists")
+	// ErrNotOwner is returned by a Store when a package is published by someone other than
+	// the user who owns it.
+	ErrNotOwner = errors.New("owned by another user")
+)
+
+// Release is a single published version of a package.
+type Release struct {
+	Package   *msg.Package `json:"package"`
+	Published time.Time    `json:"published"`
+	// Publisher is the email of the user who published the release.
+	Publisher string `json:"publisher"`
+}
+
+// User is an account which can publish packages.
+type User struct {
+	Email string `json:"email"`
+	// Password is the bcrypt hash of the user's password.
+	Password []byte `json:"password"`
+}
+
+// Key is a public key that a user signs packages with.
+type Key struct {
+	Fingerprint string `json:"fingerprint"`
+	// PublicKey is the armored public key.
+	PublicKey string    `json:"publicKey"`
+	Uploaded  time.Time `json:"uploaded"`
+	// Owner is the email of the user who uploaded the key.
+	Owner string `json:"owner"`
+}
+
+// Store is the storage backend of the registry. Implementations must be safe to use from
+// multiple goroutines.
+type Store interface {
+	// Names returns the names of all published packages in sorted order.
+	Names() ([]string, error)
+	// Releases returns the releases of a package in the order they were published, or
+	// ErrNotFound if the package has never been published.
+	Releases(name string) ([]*Release, error)
+	// AddRelease stores a new release. It returns ErrNotOwner if the package was first
+	// published by another publisher, or ErrExists if the version has already been
+	// published. Both are checked atomically with storing the release.
+	AddRelease(release *Release) error
+	// User returns the user with the given email or ErrNotFound.
+	User(email string) (*User, error)
+	// AddUser stores a new user, or returns ErrExists if the email is taken.
+	AddUser(user *User) error
+	// AddToken associates a login token with the user with the given email.
+	AddToken(token string, email string) error
+	// TokenUser returns the email of the user that owns a token, or ErrNotFound.
+	TokenUser(token string) (string, error)
+	// Key returns the public key with the given fingerprint or ErrNotFound.
+	Key(fingerprint string) (*Key, error)
+	// PutKey stores a public key, replacing any earlier upload of the same key by the same
+	// owner. It returns ErrNotOwner if the key was uploaded by someone else.
+	PutKey(key *Key) error
+}
@@ -0,0 +1,163 @@
+// Copyright 2016 Cutehacks AS. All rights reserved.
+// License can be found in the LICENSE file.
+
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	msg "qpm.io/common/messages"
+)
+
+// withStores runs test against a MemoryStore and a FileStore in a temporary directory.
+func withStores(t *testing.T, test func(t *testing.T, store Store)) {
+	dir, err := ioutil.TempDir("", "qpm-store-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file, err := NewFileStore(filepath.Join(dir, "registry.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, store := range map[string]Store{"MemoryStore": NewMemoryStore(), "FileStore": file} {
+		t.Run(name, func(t *testing.T) {
+			test(t, store)
+		})
+	}
+}
+
+func newRelease(name string, label string, publisher string) *Release {
+	return &Release{
+		Package: &msg.Package{
+			Name:    name,
+			Version: &msg.Package_Version{Label: label},
+		},
+		Published: time.Now().UTC(),
+		Publisher: publisher,
+	}
+}
+
+func TestAddReleaseChecksOwner(t *testing.T) {
+	withStores(t, func(t *testing.T, store Store) {
+		if err := store.AddRelease(newRelease("io.qpm.alpha", "1.0.0", "alice@qpm.io")); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AddRelease(newRelease("io.qpm.alpha", "1.1.0", "bob@qpm.io")); err != ErrNotOwner {
+			t.Errorf("expected ErrNotOwner for another publisher, got %v", err)
+		}
+		if err := store.AddRelease(newRelease("io.qpm.alpha", "1.0.0", "alice@qpm.io")); err != ErrExists {
+			t.Errorf("expected ErrExists for a published version, got %v", err)
+		}
+		if err := store.AddRelease(newRelease("io.qpm.alpha", "1.1.0", "alice@qpm.io")); err != nil {
+			t.Errorf("expected the owner to publish a new version, got %v", err)
+		}
+
+		releases, err := store.Releases("io.qpm.alpha")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(releases) != 2 {
+			t.Errorf("expected 2 releases, got %d", len(releases))
+		}
+	})
+}
+
+func TestAddReleaseHasOneOwner(t *testing.T) {
+	withStores(t, func(t *testing.T, store Store) {
+		// Publishers race to publish the same new package
+		const publishers = 20
+		var wg sync.WaitGroup
+		errs := make([]error, publishers)
+		for n := 0; n < publishers; n++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				errs[n] = store.AddRelease(newRelease("io.qpm.alpha", fmt.Sprintf("1.%d.0", n), fmt.Sprintf("user%d@qpm.io", n)))
+			}(n)
+		}
+		wg.Wait()
+
+		var owners []string
+		for n, err := range errs {
+			if err == nil {
+				owners = append(owners, fmt.Sprintf("user%d@qpm.io", n))
+			} else if err != ErrNotOwner {
+				t.Errorf("expected ErrNotOwner, got %v", err)
+			}
+		}
+		if len(owners) != 1 {
+			t.Fatalf("expected a single publisher to succeed, got %v", owners)
+		}
+
+		releases, err := store.Releases("io.qpm.alpha")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(releases) != 1 || releases[0].Publisher != owners[0] {
+			t.Errorf("expected only the release of %s, got %d releases", owners[0], len(releases))
+		}
+	})
+}
+
+func TestPutKeyChecksOwner(t *testing.T) {
+	withStores(t, func(t *testing.T, store Store) {
+		key := &Key{Fingerprint: "0123", PublicKey: "first", Owner: "alice@qpm.io"}
+		if err := store.PutKey(key); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutKey(&Key{Fingerprint: "0123", PublicKey: "stolen", Owner: "bob@qpm.io"}); err != ErrNotOwner {
+			t.Errorf("expected ErrNotOwner for another user, got %v", err)
+		}
+		if err := store.PutKey(&Key{Fingerprint: "0123", PublicKey: "second", Owner: "alice@qpm.io"}); err != nil {
+			t.Errorf("expected the owner to replace the key, got %v", err)
+		}
+
+		stored, err := store.Key("0123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.PublicKey != "second" || stored.Owner != "alice@qpm.io" {
+			t.Errorf("expected the owner's second upload, got %+v", stored)
+		}
+	})
+}
+
+func TestFileStoreReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qpm-store-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "registry.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.AddRelease(newRelease("io.qpm.alpha", "1.0.0", "alice@qpm.io")); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.AddToken("hashed", "alice@qpm.io"); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email, err := store.TokenUser("hashed"); err != nil || email != "alice@qpm.io" {
+		t.Errorf("expected the token to be kept, got %q, %v", email, err)
+	}
+	if err = store.AddRelease(newRelease("io.qpm.alpha", "1.1.0", "bob@qpm.io")); err != ErrNotOwner {
+		t.Errorf("expected the owner to be kept, got %v", err)
+	}
+}